@@ -1,7 +1,9 @@
 package algos
 
 import (
-	"math"
+	"context"
+	"errors"
+	"slices"
 )
 
 type ABCRequest struct {
@@ -21,6 +23,10 @@ type ABC struct {
 }
 
 func NewABC(request ABCRequest) (Algorithm, error) {
+	return newABC(request)
+}
+
+func newABC(request ABCRequest) (*ABC, error) {
 	algo, err := NewAlgo(request.AlgoRequest)
 	if err != nil {
 		return nil, err
@@ -38,46 +44,69 @@ func NewABC(request ABCRequest) (Algorithm, error) {
 	}, nil
 }
 
-func (abc *ABC) Run(send func(Response) error) ([]float64, float64) {
-	err := send(Response{
-		StepPositions: abc.Population,
-		BestPosition:  abc.GlobalBestPosition,
-		BestValue:     abc.GlobalBestValue,
-		Iteration:     0,
-	})
-	if err != nil {
-		return abc.GlobalBestPosition, abc.GlobalBestValue
+func (abc *ABC) Init(ctx context.Context) Response {
+	return abc.begin(ctx)
+}
+
+// ABCState — счётчики неудачных попыток улучшить источники в снимке
+type ABCState struct {
+	Trials []int
+}
+
+func (abc *ABC) Checkpoint() *Snapshot {
+	snapshot := abc.checkpoint("ABC")
+	snapshot.ABC = &ABCState{Trials: slices.Clone(abc.Trials)}
+	return snapshot
+}
+
+func (abc *ABC) Resume(ctx context.Context, snapshot *Snapshot) (Response, error) {
+	if err := abc.restoreTrials(snapshot, "ABC"); err != nil {
+		return Response{}, err
 	}
+	return abc.resume(ctx), nil
+}
 
-	for t := range abc.Iterations {
-		abc.foragerPhase()
-		abc.observerPhase()
-		abc.scoutPhase()
-
-		err = send(Response{
-			StepPositions: abc.Population,
-			BestPosition:  abc.GlobalBestPosition,
-			BestValue:     abc.GlobalBestValue,
-			Iteration:     t + 1,
-		})
-		if err != nil {
-			return abc.GlobalBestPosition, abc.GlobalBestValue
-		}
+func (abc *ABC) restoreTrials(snapshot *Snapshot, name string) error {
+	if snapshot.ABC == nil || len(snapshot.ABC.Trials) != abc.PopulationSize {
+		return errors.New("в снимке нет счётчиков попыток")
 	}
+	if err := abc.restore(snapshot, name); err != nil {
+		return err
+	}
+	abc.Trials = slices.Clone(snapshot.ABC.Trials)
+	return nil
+}
+
+func (abc *ABC) Step() (Response, bool) {
+	return abc.advance(abc.iterate)
+}
+
+func (abc *ABC) Run(ctx context.Context, send func(Response) error) ([]float64, float64) {
+	return abc.drive(abc.Init(ctx), abc.Step, send)
+}
+
+// iterate выполняет итерацию t и возвращает положения агентов для ответа
+func (abc *ABC) iterate(t int) [][]float64 {
+	abc.foragerPhase()
+	abc.observerPhase()
+	abc.scoutPhase()
 
-	return abc.GlobalBestPosition, abc.GlobalBestValue
+	return abc.Population
 }
 
 func (abc *ABC) foragerPhase() {
 	for i := range abc.ForagerSize {
+		if abc.done() {
+			return
+		}
 		k := abc.Rng.Intn(abc.ForagerSize) // другой собиратель
 		for k == i {
 			k = abc.Rng.Intn(abc.ForagerSize)
 		}
 		candidate := abc.mutate(abc.Population[i], abc.Population[k])
 
-		if abc.Func(candidate) < abc.Func(abc.Population[i]) {
-			abc.Population[i] = candidate
+		if value := abc.Func(candidate); value < abc.Fitness[i] {
+			abc.moveAgent(i, candidate, value)
 			abc.Trials[i] = 0
 			abc.updateGlobalBest(i)
 		} else {
@@ -88,6 +117,9 @@ func (abc *ABC) foragerPhase() {
 
 func (abc *ABC) observerPhase() {
 	for i := abc.ForagerSize; i < abc.ForagerSize+abc.ObserverSize; i++ {
+		if abc.done() {
+			return
+		}
 		j := abc.selectForagerByFitness()  // собиратель
 		k := abc.Rng.Intn(abc.ForagerSize) // другой собиратель
 		for k == j {
@@ -95,8 +127,8 @@ func (abc *ABC) observerPhase() {
 		}
 		candidate := abc.mutate(abc.Population[j], abc.Population[k])
 
-		if abc.Func(candidate) < abc.Func(abc.Population[j]) {
-			abc.Population[j] = candidate
+		if value := abc.Func(candidate); value < abc.Fitness[j] {
+			abc.moveAgent(j, candidate, value)
 			abc.Trials[j] = 0
 			abc.updateGlobalBest(j)
 		} else {
@@ -107,8 +139,12 @@ func (abc *ABC) observerPhase() {
 
 func (abc *ABC) scoutPhase() {
 	for i := range abc.ForagerSize { // Только собиратели могут стать разведчиками
+		if abc.done() {
+			return
+		}
 		if abc.Trials[i] > abc.Limit {
-			abc.Population[i] = abc.randomSolution()
+			solution := abc.randomSolution()
+			abc.moveAgent(i, solution, abc.Func(solution))
 			abc.Trials[i] = 0
 			abc.updateGlobalBest(i)
 		}
@@ -120,20 +156,20 @@ func (abc *ABC) mutate(solution, other []float64) []float64 {
 	copy(newSolution, solution)
 	s := abc.Rng.Intn(len(solution))
 	r := abc.Rng.Float64()*2 - 1
-	newSolution[s] = math.Max(abc.Bounds[s][0], math.Min(solution[s]+r*(solution[s]-other[s]), abc.Bounds[s][1]))
-	return newSolution
+	newSolution[s] = solution[s] + r*(solution[s]-other[s])
+	return abc.confine(newSolution, solution)
 }
 
 func (abc *ABC) selectForagerByFitness() int {
 	sumFitness := 0.0
 	for i := range abc.ForagerSize {
-		sumFitness += abc.Func(abc.Population[i])
+		sumFitness += abc.Fitness[i]
 	}
 
 	threshold := abc.Rng.Float64() * sumFitness
 	sum := 0.0
 	for i := range abc.ForagerSize {
-		sum += abc.Func(abc.Population[i])
+		sum += abc.Fitness[i]
 		if sum >= threshold {
 			return i
 		}
@@ -146,13 +182,12 @@ func (abc *ABC) randomSolution() []float64 {
 	for i := range solution {
 		solution[i] = abc.Bounds[i][0] + abc.Rng.Float64()*(abc.Bounds[i][1]-abc.Bounds[i][0])
 	}
-	return solution
+	return abc.repair(solution)
 }
 
 func (abc *ABC) updateGlobalBest(i int) {
-	value := abc.Func(abc.Population[i])
+	value := abc.Fitness[i]
 	if value < abc.GlobalBestValue {
-		abc.GlobalBestValue = value
-		copy(abc.GlobalBestPosition, abc.Population[i])
+		abc.setBest(slices.Clone(abc.Population[i]), value, i)
 	}
 }
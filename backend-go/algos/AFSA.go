@@ -1,8 +1,10 @@
 package algos
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"math"
+	"slices"
 )
 
 type AFSARequest struct {
@@ -25,6 +27,9 @@ type AFSA struct {
 
 	HistoryBest []float64
 	Visual      float64
+
+	stagnationCount int
+	distanceMatrix  [][]float64
 }
 
 func NewAFSA(request AFSARequest) (Algorithm, error) {
@@ -46,101 +51,134 @@ func NewAFSA(request AFSARequest) (Algorithm, error) {
 	}, nil
 }
 
-func (afsa *AFSA) Run(send func(Response) error) ([]float64, float64) {
-	err := send(Response{
-		StepPositions: afsa.Population,
-		BestPosition:  afsa.GlobalBestPosition,
-		BestValue:     afsa.GlobalBestValue,
-		Iteration:     0,
-	})
-	if err != nil {
-		fmt.Println("CONN ERR", err.Error())
-		return afsa.GlobalBestPosition, afsa.GlobalBestValue
-	}
-	stagnationCount := 0
+func (afsa *AFSA) Init(ctx context.Context) Response {
+	afsa.stagnationCount = 0
 
-	distanceMatrix := make([][]float64, afsa.PopulationSize)
+	afsa.distanceMatrix = make([][]float64, afsa.PopulationSize)
 	for i := 0; i < afsa.PopulationSize; i++ {
-		distanceMatrix[i] = make([]float64, afsa.PopulationSize)
+		afsa.distanceMatrix[i] = make([]float64, afsa.PopulationSize)
 		for j := 0; j < afsa.PopulationSize; j++ {
-			distanceMatrix[i][j] = math.Sqrt(afsa.calculateDistance(afsa.Population[i], afsa.Population[j]))
+			afsa.distanceMatrix[i][j] = math.Sqrt(afsa.calculateDistance(afsa.Population[i], afsa.Population[j]))
 		}
 	}
+	return afsa.begin(ctx)
+}
 
-	for t := range afsa.Iterations {
-		stepPositions := make([][]float64, 0)
+// AFSAState — история лучших значений, радиус видимости, счётчик застоя
+// и матрица расстояний в снимке
+type AFSAState struct {
+	HistoryBest     []float64
+	Visual          float64
+	StagnationCount int
+	DistanceMatrix  [][]float64
+}
 
-		afsa.Visual = math.Max(afsa.MinVisual, afsa.InitialVisual*(1-float64(t)/float64(afsa.Iterations)))
+func (afsa *AFSA) Checkpoint() *Snapshot {
+	snapshot := afsa.checkpoint("AFSA")
+	snapshot.AFSA = &AFSAState{
+		HistoryBest:     slices.Clone(afsa.HistoryBest),
+		Visual:          afsa.Visual,
+		StagnationCount: afsa.stagnationCount,
+		DistanceMatrix:  cloneMatrix(afsa.distanceMatrix),
+	}
+	return snapshot
+}
 
-		for i := range afsa.PopulationSize {
-			var newPosition []float64
-			neighbors := afsa.findNeighbors(i, distanceMatrix)
+func (afsa *AFSA) Resume(ctx context.Context, snapshot *Snapshot) (Response, error) {
+	state := snapshot.AFSA
+	if state == nil || len(state.DistanceMatrix) != afsa.PopulationSize {
+		return Response{}, errors.New("в снимке нет состояния косяка рыб")
+	}
+	if err := afsa.restore(snapshot, "AFSA"); err != nil {
+		return Response{}, err
+	}
+	afsa.HistoryBest = slices.Clone(state.HistoryBest)
+	afsa.Visual = state.Visual
+	afsa.stagnationCount = state.StagnationCount
+	afsa.distanceMatrix = cloneMatrix(state.DistanceMatrix)
+	return afsa.resume(ctx), nil
+}
 
-			if len(neighbors) == 0 {
-				newPosition = afsa.randomMove(afsa.Population[i])
+func (afsa *AFSA) Step() (Response, bool) {
+	return afsa.advance(afsa.iterate)
+}
+
+func (afsa *AFSA) Run(ctx context.Context, send func(Response) error) ([]float64, float64) {
+	return afsa.drive(afsa.Init(ctx), afsa.Step, send)
+}
+
+// iterate выполняет итерацию t и возвращает положения агентов для ответа
+func (afsa *AFSA) iterate(t int) [][]float64 {
+	stepPositions := make([][]float64, 0)
+
+	afsa.Visual = math.Max(afsa.MinVisual, afsa.InitialVisual*(1-float64(t)/float64(afsa.Iterations)))
+
+	for i := range afsa.PopulationSize {
+		if afsa.done() {
+			stepPositions = append(stepPositions, afsa.Population[i:]...)
+			break
+		}
+		var newPosition []float64
+		neighbors := afsa.findNeighbors(i, afsa.distanceMatrix)
+
+		if len(neighbors) == 0 {
+			newPosition = afsa.randomMove(afsa.Population[i])
+		} else {
+			if float64(len(neighbors))/float64(afsa.PopulationSize) > afsa.Teta {
+				newPosition = afsa.searchBehavior(i, neighbors)
 			} else {
-				if float64(len(neighbors))/float64(afsa.PopulationSize) > afsa.Teta {
-					newPosition = afsa.searchBehavior(i, neighbors)
+				c_i := afsa.repair(afsa.meanPosition(neighbors))
+				if afsa.Func(c_i) < afsa.Fitness[i] {
+					newPosition = afsa.swarmBehavior(c_i, afsa.Population[i])
 				} else {
-					c_i := afsa.meanPosition(neighbors)
-					if afsa.Func(c_i) < afsa.Func(afsa.Population[i]) {
-						newPosition = afsa.swarmBehavior(c_i, afsa.Population[i])
+					newPosition = afsa.searchBehavior(i, neighbors)
+				}
+
+				jStar := afsa.bestNeighbor(neighbors)
+				if jStar != -1 {
+					if afsa.Fitness[jStar] < afsa.Fitness[i] {
+						newPosition = afsa.chaseBehavior(i, jStar)
 					} else {
 						newPosition = afsa.searchBehavior(i, neighbors)
 					}
-
-					jStar := afsa.bestNeighbor(neighbors)
-					if jStar != -1 {
-						if afsa.Func(afsa.Population[jStar]) < afsa.Func(afsa.Population[i]) {
-							newPosition = afsa.chaseBehavior(i, jStar)
-						} else {
-							newPosition = afsa.searchBehavior(i, neighbors)
-						}
-					}
-
 				}
-			}
 
-			fNewPosition := afsa.Func(newPosition)
-			fCurrentPosition := afsa.Func(afsa.Population[i])
-
-			if fNewPosition < fCurrentPosition {
-				afsa.Population[i] = newPosition
 			}
+		}
 
-			if fNewPosition < afsa.GlobalBestValue {
-				afsa.GlobalBestValue = fNewPosition
-				afsa.GlobalBestPosition = newPosition
-			}
+		fNewPosition := afsa.Func(newPosition)
 
-			stepPositions = append(stepPositions, afsa.Population[i])
+		moved := fNewPosition < afsa.Fitness[i]
+		if moved {
+			afsa.moveAgent(i, newPosition, fNewPosition)
 		}
 
-		err = send(Response{
-			StepPositions: stepPositions,
-			BestPosition:  afsa.GlobalBestPosition,
-			BestValue:     afsa.GlobalBestValue,
-			Iteration:     (t + 1),
-		})
-		if err != nil {
-			fmt.Println("ERROR", err.Error())
-			return afsa.GlobalBestPosition, afsa.GlobalBestValue
+		if fNewPosition < afsa.GlobalBestValue {
+			agent := -1
+			if moved {
+				agent = i
+			}
+			afsa.setBest(newPosition, fNewPosition, agent)
 		}
 
-		afsa.HistoryBest = append(afsa.HistoryBest, afsa.GlobalBestValue)
+		stepPositions = append(stepPositions, afsa.Population[i])
+	}
 
-		if t > 0 && math.Abs(afsa.HistoryBest[len(afsa.HistoryBest)-1]-afsa.HistoryBest[len(afsa.HistoryBest)-2]) < afsa.Eta {
-			stagnationCount++
-		} else {
-			stagnationCount = 0
-		}
+	afsa.HistoryBest = append(afsa.HistoryBest, afsa.GlobalBestValue)
 
-		if stagnationCount > afsa.MaxTries {
-			j := afsa.Rng.Intn(afsa.PopulationSize)
-			afsa.Population[j] = afsa.jumpBehavior(afsa.Population[j])
-		}
+	if t > 0 && math.Abs(afsa.HistoryBest[len(afsa.HistoryBest)-1]-afsa.HistoryBest[len(afsa.HistoryBest)-2]) < afsa.Eta {
+		afsa.stagnationCount++
+	} else {
+		afsa.stagnationCount = 0
+	}
+
+	if afsa.stagnationCount > afsa.MaxTries {
+		j := afsa.Rng.Intn(afsa.PopulationSize)
+		position := afsa.jumpBehavior(afsa.Population[j])
+		afsa.moveAgent(j, position, afsa.Func(position))
 	}
-	return afsa.GlobalBestPosition, afsa.GlobalBestValue
+
+	return stepPositions
 }
 
 func (afsa *AFSA) findNeighbors(index int, distanceMatrix [][]float64) []int {
@@ -165,29 +203,28 @@ func (afsa *AFSA) randomMove(currentPosition []float64) []float64 {
 		boundMin, boundMax := afsa.Bounds[j][0], afsa.Bounds[j][1]
 
 		delta := (afsa.Rng.Float64()*2 - 1) * math.Min(v, boundMax-boundMin)
-		newVal := currentPosition[j] + delta
-		newPosition[j] = math.Max(boundMin, math.Min(newVal, boundMax))
+		newPosition[j] = currentPosition[j] + delta
 	}
 
-	return newPosition
+	return afsa.confine(newPosition, currentPosition)
 }
 
 func (afsa *AFSA) chaseBehavior(i, jStar int) []float64 {
 	r := afsa.Rng.Float64()
 	newPosition := make([]float64, afsa.NumDimensions)
 	for j := range afsa.NumDimensions {
-		newPosition[j] = math.Max(afsa.Bounds[j][0], math.Min(afsa.Population[i][j]+r*(afsa.Population[jStar][j]-afsa.Population[i][j]), afsa.Bounds[j][1]))
+		newPosition[j] = afsa.Population[i][j] + r*(afsa.Population[jStar][j]-afsa.Population[i][j])
 	}
-	return newPosition
+	return afsa.confine(newPosition, afsa.Population[i])
 }
 
 func (afsa *AFSA) swarmBehavior(c_i, x_i []float64) []float64 {
 	r := afsa.Rng.Float64()
 	newPosition := make([]float64, afsa.NumDimensions)
 	for j := range afsa.NumDimensions {
-		newPosition[j] = math.Max(afsa.Bounds[j][0], math.Min(x_i[j]+r*(c_i[j]-x_i[j]), afsa.Bounds[j][1]))
+		newPosition[j] = x_i[j] + r*(c_i[j]-x_i[j])
 	}
-	return newPosition
+	return afsa.confine(newPosition, x_i)
 }
 
 func (afsa *AFSA) searchBehavior(i int, V_i []int) []float64 {
@@ -195,9 +232,9 @@ func (afsa *AFSA) searchBehavior(i int, V_i []int) []float64 {
 	r := afsa.Rng.Float64()
 	newPosition := make([]float64, afsa.NumDimensions)
 	for k := range afsa.NumDimensions {
-		newPosition[k] = math.Max(afsa.Bounds[k][0], math.Min(afsa.Population[i][k]+r*(afsa.Population[j][k]-afsa.Population[i][k]), afsa.Bounds[k][1]))
+		newPosition[k] = afsa.Population[i][k] + r*(afsa.Population[j][k]-afsa.Population[i][k])
 	}
-	return newPosition
+	return afsa.confine(newPosition, afsa.Population[i])
 }
 
 func (afsa *AFSA) jumpBehavior(x_i []float64) []float64 {
@@ -211,11 +248,10 @@ func (afsa *AFSA) jumpBehavior(x_i []float64) []float64 {
 		direction := (afsa.Rng.Float64()*2 - 1) * p
 		delta := direction * rangeJ
 
-		newVal := x_i[j] + delta
-		newPosition[j] = math.Max(boundMin, math.Min(newVal, boundMax))
+		newPosition[j] = x_i[j] + delta
 	}
 
-	return newPosition
+	return afsa.confine(newPosition, x_i)
 }
 
 func (afsa *AFSA) bestNeighbor(V_i []int) int {
@@ -223,10 +259,10 @@ func (afsa *AFSA) bestNeighbor(V_i []int) int {
 		return -1
 	}
 	bestIndex := V_i[0]
-	bestValue := afsa.Func(afsa.Population[bestIndex])
+	bestValue := afsa.Fitness[bestIndex]
 	for _, index := range V_i[1:] {
-		if afsa.Func(afsa.Population[index]) < bestValue {
-			bestValue = afsa.Func(afsa.Population[index])
+		if afsa.Fitness[index] < bestValue {
+			bestValue = afsa.Fitness[index]
 			bestIndex = index
 		}
 	}
@@ -1,68 +1,294 @@
 package algos
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"slices"
+	"strings"
 	"time"
-
-	"github.com/expr-lang/expr"
-	"github.com/seehuhn/mt19937"
 )
 
+// Algorithm выполняется либо целиком через Run, либо по итерациям:
+// Init, затем Step, пока он возвращает true. Методы нельзя вызывать одновременно.
 type Algorithm interface {
-	Run(send func(Response) error) ([]float64, float64)
+	// Run останавливается при отмене ctx, по истечении TimeLimit или когда send вернёт ошибку
+	Run(ctx context.Context, send func(Response) error) ([]float64, float64)
+	// Init готовит алгоритм к работе и возвращает ответ с начальной популяцией
+	Init(ctx context.Context) Response
+	// Step выполняет одну итерацию; false — алгоритм уже завершил работу
+	// и возвращён последний ответ
+	Step() (Response, bool)
+	// State возвращает последний ответ
+	State() Response
+	// Checkpoint возвращает снимок состояния между итерациями
+	Checkpoint() *Snapshot
+	// Resume вместо Init восстанавливает алгоритм, созданный по тому же запросу,
+	// из снимка и возвращает последний ответ перед снимком
+	Resume(ctx context.Context, snapshot *Snapshot) (Response, error)
+	// Close освобождает ресурсы целевой функции, например внешние процессы
+	Close() error
 }
 
 type AlgoRequest struct {
-	Func           string      `json:"targetFunction"`
-	Iterations     int         `json:"maxIter"`
+	Func       string `json:"targetFunction"`
+	Iterations int    `json:"maxIter"`
+	// бюджет вычислений целевой функции: алгоритм останавливается, израсходовав его
+	MaxEvaluations *int `json:"maxEvaluations,omitempty"`
+	// ограничение времени работы алгоритма в секундах
+	TimeLimit      *float64    `json:"timeLimit,omitempty"`
 	Bounds         [][]float64 `json:"bounds,omitempty"`
 	Population     [][]float64 `json:"initialPopulation,omitempty"`
 	PopulationSize *int        `json:"populationSize,omitempty"`
 	Seed           *int        `json:"seed,omitempty"`
+	Benchmark      string      `json:"benchmark,omitempty"`
+	Direction      string      `json:"objective,omitempty"`
+
+	Constraints *ConstraintsRequest `json:"constraints,omitempty"`
+	Noise       *NoiseRequest       `json:"noise,omitempty"`
+	// целевая функция во внешней программе вместо targetFunction
+	External *ExternalRequest `json:"external,omitempty"`
+	// целевая функция на HTTP-сервере вместо targetFunction
+	HTTP *HTTPObjectiveRequest `json:"http,omitempty"`
+	// сдвиг, поворот, масштаб и добавка к целевой функции
+	Transform *TransformRequest `json:"transform,omitempty"`
+	// подбор параметров модели по данным: решение — вектор параметров
+	Fit *FitRequest `json:"fit,omitempty"`
+	// типы координат: целые, дискретные, категориальные, двоичные; по умолчанию непрерывные
+	Variables []VariableRequest `json:"variables,omitempty"`
+	// что делать с агентом, вышедшим за границы поиска; по умолчанию — перенести на границу
+	Boundary *BoundaryRequest `json:"boundary,omitempty"`
+	// способ создания начальной популяции; по умолчанию — равномерно в границах поиска
+	Initialization *InitializationRequest `json:"initialization,omitempty"`
+
+	// зарегистрированная в Go целевая функция вместо targetFunction
+	Native string `json:"nativeFunction,omitempty"`
+	// градиентное уточнение лучшего решения (меметический вариант алгоритма)
+	LocalSearch *LocalSearchRequest `json:"localSearch,omitempty"`
+
+	// досрочная остановка: целевое значение, отсутствие улучшения, схождение популяции
+	Stopping *StoppingRequest `json:"stopping,omitempty"`
+	// дополнительные критерии остановки для вызова из Go
+	Criteria []StoppingCriterion `json:"-"`
+
+	MovingPeaks     *MovingPeaksRequest     `json:"movingPeaks,omitempty"`
+	ChangeDetection *ChangeDetectionRequest `json:"changeDetection,omitempty"`
+
+	// вспомогательные функции для выражений: "r(a, b) = sqrt(a^2 + b^2)"
+	Helpers []string `json:"helpers,omitempty"`
+
+	// несколько целевых функций для многокритериальных алгоритмов
+	Objectives  []string `json:"objectives,omitempty"`
+	ArchiveSize *int     `json:"archiveSize,omitempty"`
+	// запрос создаёт многокритериальный алгоритм; выставляется его конструктором
+	multiObjective bool
 
 	NumDimensions *int `json:"numDimensions"`
 }
 
 type Algo struct {
-	Func           func([]float64) float64
-	Iterations     int
-	Bounds         [][]float64
-	Population     [][]float64
+	// функция, которую минимизируют алгоритмы; при наличии ограничений
+	// это Objective со штрафом за их нарушение
+	Func        func([]float64) float64
+	Objective   func([]float64) float64
+	Constraints *Constraints
+	// при максимизации Objective — целевая функция с обратным знаком
+	Maximize bool
+	// шум в Objective; exactObjective — та же функция без шума
+	Noise          *Noise
+	exactObjective func([]float64) float64
+
+	// внешний вычислитель Objective (программа или HTTP-сервер)
+	Batch BatchObjective
+
+	// преобразование пространства поиска, применённое к целевым функциям
+	Transform *Transform
+
+	// задача подбора параметров модели; в последнем ответе — подобранная кривая
+	Fit *Fit
+
+	// локальный поиск из лучшего решения в конце работы или каждые Every итераций
+	LocalSearch *LocalSearch
+
+	// критерии досрочной остановки, проверяемые после каждой итерации,
+	// и лучшее значение Func в начале работы и после каждой итерации
+	Stopping []StoppingCriterion
+	History  []float64
+
+	// критерии многокритериальной задачи и архив недоминируемых решений;
+	// Func в этом случае — первый критерий
+	Objectives []func([]float64) float64
+	Archive    *ParetoArchive
+
+	Iterations int
+	// ограничение времени работы; 0 — без ограничения
+	TimeLimit time.Duration
+	Bounds    [][]float64
+	// типы координат; nil — все координаты непрерывны
+	Variables []Variable
+	// обработка выхода агентов за границы поиска
+	Boundary   *Boundary
+	Population [][]float64
+	// значения Func в точках Population; обновляются только при перемещении агента
+	Fitness        []float64
 	PopulationSize int
 	NumDimensions  int
 
 	GlobalBestPosition []float64
 	GlobalBestValue    float64
+	// номер агента, стоящего в GlobalBestPosition; -1 — такого агента нет
+	bestAgent int
+
+	// известный глобальный оптимум тестовой функции, если он задан
+	OptimumPosition []float64
+	OptimumValue    *float64
+
+	// нестационарные задачи
+	Clock           *Clock
+	MovingPeaks     *MovingPeaks
+	ChangeDetection *ChangeDetection
+	changeDetected  bool
+
+	// номер следующей итерации, последний ответ и отмена ограничения времени
+	next   int
+	state  Response
+	cancel context.CancelFunc
+	// сработавший критерий остановки
+	stopped string
+	// Fitness пересчитаны в начале текущей итерации: функция изменилась
+	refreshed bool
+	// целевая функция явно зависит от времени, и Fitness пересчитывается каждую итерацию
+	timeDependent bool
 
 	Rng *rand.Rand
+	// источник Rng, состояние которого сохраняется в снимке
+	source *generator
 }
 
 func NewAlgo(request AlgoRequest) (*Algo, error) {
+	algo, err := newProblem(request)
+	if err != nil {
+		return nil, err
+	}
+	if request.multiObjective && algo.Objectives == nil {
+		algo.Close()
+		return nil, errors.New("многокритериальному алгоритму нужно не меньше двух целевых функций")
+	}
+	if !request.multiObjective && algo.Objectives != nil {
+		algo.Close()
+		return nil, errors.New("алгоритм однокритериальный: для нескольких целевых функций используйте MOGWO, MOFA или MOABC")
+	}
+	if err := algo.initPopulation(request); err != nil {
+		algo.Close()
+		return nil, err
+	}
+	return algo, nil
+}
+
+// newProblem строит целевую функцию, ограничения и область поиска задачи
+// без начальной популяции
+func newProblem(request AlgoRequest) (*Algo, error) {
+	// целевая функция задаётся одним способом; выражение Func используется,
+	// только если не задан ни один из остальных
+	var sources []string
+	if request.Benchmark != "" {
+		sources = append(sources, "тестовой функцией")
+	}
+	if request.Native != "" {
+		sources = append(sources, "зарегистрированной функцией")
+	}
+	if request.Fit != nil {
+		sources = append(sources, "подбором модели")
+	}
+	if request.External != nil {
+		sources = append(sources, "внешней программой")
+	}
+	if request.HTTP != nil {
+		sources = append(sources, "HTTP-сервером")
+	}
+	if len(request.Objectives) > 0 {
+		sources = append(sources, "списком критериев")
+	}
+	if len(sources) > 1 {
+		return nil, fmt.Errorf("целевая функция задана одновременно %s", strings.Join(sources, " и "))
+	}
+
+	var benchmark *Benchmark
+	var multiBenchmark *MultiObjectiveBenchmark
+	movingPeaks := request.Benchmark == MovingPeaksBenchmark
+	if movingPeaks {
+		if request.NumDimensions == nil && request.Bounds == nil && request.Population == nil {
+			dimensions := movingPeaksDimensions
+			request.NumDimensions = &dimensions
+		}
+	} else if request.Benchmark != "" {
+		if b, ok := Benchmarks[request.Benchmark]; ok {
+			benchmark = &b
+			if b.Dimensions > 0 && request.NumDimensions == nil {
+				request.NumDimensions = &b.Dimensions
+			}
+		} else if b, ok := MultiObjectiveBenchmarks[request.Benchmark]; ok {
+			multiBenchmark = &b
+			if request.NumDimensions == nil && request.Bounds == nil && request.Population == nil {
+				request.NumDimensions = &b.Dimensions
+			}
+		} else {
+			return nil, fmt.Errorf("неизвестная тестовая функция %q", request.Benchmark)
+		}
+	}
+
+	var native *NativeFunction
+	if request.Native != "" {
+		f, ok := LookupFunction(request.Native)
+		if !ok {
+			return nil, fmt.Errorf("функция %q не зарегистрирована", request.Native)
+		}
+		native = &f
+		if f.Dimensions > 0 && request.NumDimensions == nil {
+			request.NumDimensions = &f.Dimensions
+		}
+		if len(f.Bounds) > 1 && request.Bounds == nil {
+			request.Bounds = f.bounds(f.Dimensions)
+		}
+	}
 
-	if request.Bounds != nil && len(request.Bounds) != 2 {
-		return nil, errors.New("неверно заданы границы поиска")
+	var fit *Fit
+	if request.Fit != nil {
+		var err error
+		if fit, err = NewFit(*request.Fit); err != nil {
+			return nil, err
+		}
+		if request.NumDimensions == nil && request.Bounds == nil && request.Population == nil {
+			dimensions := len(fit.Parameters)
+			request.NumDimensions = &dimensions
+		}
+	}
+
+	for _, bound := range request.Bounds {
+		if len(bound) != 2 {
+			return nil, errors.New("неверно заданы границы поиска")
+		}
 	}
 
 	if request.Population != nil && len(request.Population) < 1 {
 		return nil, errors.New("пустая популяция")
 	}
 
-	if request.Population == nil && request.PopulationSize == nil {
-		return nil, errors.New("недостаточно данных для создания популяции")
+	if request.ArchiveSize != nil && *request.ArchiveSize < 1 {
+		return nil, errors.New("размер архива должен быть больше 0")
 	}
 
 	if request.NumDimensions == nil && len(request.Population) < 1 && request.Bounds == nil {
 		return nil, errors.New("не задана размерность задачи")
 	}
 
-	if request.Bounds != nil && request.Population != nil && len(request.Bounds[0]) != len(request.Population[0]) {
+	if request.Bounds != nil && request.Population != nil && len(request.Bounds) != len(request.Population[0]) {
 		return nil, errors.New("несоответствие размерностей границ и популяции")
 	}
 
-	if request.Bounds != nil && request.NumDimensions != nil && len(request.Bounds[0]) != *request.NumDimensions {
+	if request.Bounds != nil && request.NumDimensions != nil && len(request.Bounds) != *request.NumDimensions {
 		return nil, errors.New("несоответствие размерности границ и размерности задачи")
 	}
 
@@ -70,104 +296,786 @@ func NewAlgo(request AlgoRequest) (*Algo, error) {
 		return nil, errors.New("несоответствие размерности популяции и размерности задачи")
 	}
 
-	function, err := ConvertMathExpressionToFunc(request.Func)
+	clock := &Clock{}
+
+	helpers, err := NewHelpers(request.Helpers)
+	if err != nil {
+		return nil, err
+	}
+
+	var function func([]float64) float64
+	var objectives []func([]float64) float64
+	var peaks *MovingPeaks
+	var batch BatchObjective
+	var expression *Expression
+	// точный градиент целевой функции для локального поиска, если он известен
+	var gradient func([]float64) (float64, []float64, error)
+	timeDependent := false
+	requiredDimensions := 0
+	defaultBound := []float64{-100, 100}
+	switch {
+	case movingPeaks:
+		// пики создаются ниже, когда станут известны границы поиска
+		function = func(position []float64) float64 {
+			return peaks.Func(position)
+		}
+		defaultBound = movingPeaksBounds[:]
+	case benchmark != nil:
+		function = benchmark.Func
+		requiredDimensions = benchmark.Dimensions
+		defaultBound = benchmark.Bounds[:]
+	case multiBenchmark != nil:
+		objectives = slices.Clone(multiBenchmark.Objectives)
+		requiredDimensions = len(objectives)
+		defaultBound = multiBenchmark.Bounds[:]
+	case native != nil:
+		function = native.Func
+		requiredDimensions = native.Dimensions
+		if len(native.Bounds) == 1 {
+			defaultBound = native.Bounds[0]
+		}
+		if native.Batch != nil {
+			batch = &nativeBatch{name: request.Native, function: *native}
+		}
+		if native.Gradient != nil {
+			gradient = func(position []float64) (float64, []float64, error) {
+				value, gradient := native.Gradient(position)
+				return value, gradient, nil
+			}
+		}
+	case fit != nil:
+		function = fit.Func
+		requiredDimensions = len(fit.Parameters)
+	case request.External != nil:
+		external, err := NewExternal(*request.External)
+		if err != nil {
+			return nil, err
+		}
+		external.clock = clock
+		batch = external
+		function = external.Eval
+	case request.HTTP != nil:
+		remote, err := NewHTTPObjective(*request.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		remote.clock = clock
+		batch = remote
+		function = remote.Eval
+	case len(request.Objectives) > 0:
+		for _, objective := range request.Objectives {
+			expression, err := CompileExpressionWithHelpers(objective, helpers)
+			if err != nil {
+				return nil, errors.New("Ошибка компиляции функции:" + err.Error())
+			}
+			expression.clock = clock
+			timeDependent = timeDependent || expression.TimeDependent
+			objectives = append(objectives, expression.Eval)
+			requiredDimensions = max(requiredDimensions, expression.Dimensions)
+		}
+	default:
+		expression, err = CompileExpressionWithHelpers(request.Func, helpers)
+		if err != nil {
+			return nil, errors.New("Ошибка компиляции функции:" + err.Error())
+		}
+		expression.clock = clock
+		timeDependent = expression.TimeDependent
+		function = expression.Eval
+		requiredDimensions = expression.Dimensions
+		gradient = expression.Gradient
+	}
+	if len(objectives) == 1 {
+		function = objectives[0]
+		objectives = nil
+	}
+
+	// преобразование создаётся ниже, когда станут известны границы поиска
+	var transform *Transform
+	if request.Transform != nil {
+		transformed := func(function func([]float64) float64) func([]float64) float64 {
+			return func(position []float64) float64 {
+				return function(transform.Apply(position)) + transform.Bias
+			}
+		}
+		if objectives != nil {
+			for i := range objectives {
+				objectives[i] = transformed(objectives[i])
+			}
+		} else {
+			function = transformed(function)
+		}
+	}
+
+	maximize := false
+	switch request.Direction {
+	case "", "min":
+	case "max":
+		maximize = true
+		function = negate(function)
+		for i := range objectives {
+			objectives[i] = negate(objectives[i])
+		}
+	default:
+		return nil, fmt.Errorf("неизвестное направление оптимизации %q", request.Direction)
+	}
+
+	if request.MaxEvaluations != nil {
+		if *request.MaxEvaluations < 1 {
+			return nil, errors.New("бюджет вычислений должен быть больше 0")
+		}
+		clock.budget = int64(*request.MaxEvaluations)
+	}
+
+	var timeLimit time.Duration
+	if request.TimeLimit != nil {
+		if *request.TimeLimit <= 0 {
+			return nil, errors.New("ограничение времени должно быть больше 0")
+		}
+		timeLimit = time.Duration(*request.TimeLimit * float64(time.Second))
+	}
+
+	// exactObjective не считается в бюджете: она нужна только для отчёта;
+	// вектор критериев считается одним вычислением
+	var exactObjective func([]float64) float64
+	if objectives != nil {
+		exactObjective = objectives[0]
+		objectives[0] = clock.counting(objectives[0])
+		function = objectives[0]
+	} else {
+		exactObjective = function
+		function = clock.counting(function)
+	}
+
+	seed := time.Now().UnixNano()
+	if request.Seed != nil {
+		seed = int64(*request.Seed)
+	}
+
+	var noise *Noise
+	if request.Noise != nil {
+		var err error
+		noise, err = NewNoise(*request.Noise, seed+1)
+		if err != nil {
+			return nil, err
+		}
+		for i := range objectives {
+			objectives[i] = noise.wrap(objectives[i])
+		}
+		if objectives != nil {
+			function = objectives[0]
+		} else {
+			function = noise.wrap(function)
+		}
+	}
+
+	if request.Constraints != nil && objectives != nil {
+		return nil, errors.New("ограничения не поддерживаются в многокритериальных задачах")
+	}
+
+	var localSearch *LocalSearch
+	if request.LocalSearch != nil {
+		if objectives != nil {
+			return nil, errors.New("локальный поиск не поддерживается в многокритериальных задачах")
+		}
+		if localSearch, err = NewLocalSearch(*request.LocalSearch); err != nil {
+			return nil, err
+		}
+		// точный градиент годится, пока к функции не добавлены
+		// преобразование, шум и ограничения
+		if gradient != nil && request.Transform == nil && request.Noise == nil && request.Constraints == nil {
+			localSearch.gradient = gradient
+			if maximize {
+				localSearch.gradient = func(position []float64) (float64, []float64, error) {
+					value, g, err := gradient(position)
+					return -value, scaled(g, -1), err
+				}
+			}
+		}
+	}
+
+	boundary, err := NewBoundary(setDefault(request.Boundary, BoundaryRequest{}))
 	if err != nil {
-		return nil, errors.New("Ошибка компиляции функции:" + err.Error())
+		return nil, err
+	}
+
+	stopping := slices.Clone(request.Criteria)
+	if request.Stopping != nil {
+		criteria, err := NewStoppingCriteria(*request.Stopping)
+		if err != nil {
+			return nil, err
+		}
+		stopping = append(stopping, criteria...)
+	}
+
+	var constraints *Constraints
+	if request.Constraints != nil {
+		var dimensions int
+		var err error
+		constraints, dimensions, err = NewConstraints(*request.Constraints, request.Iterations, clock, helpers)
+		if err != nil {
+			return nil, err
+		}
+		requiredDimensions = max(requiredDimensions, dimensions)
+		// если функция и ограничения не зависят от времени, с номером итерации
+		// меняется только приспособленность, и значения в точках можно сохранять
+		if constraints.changing() && !constraints.timeDependent && !timeDependent && noise == nil && !movingPeaks {
+			constraints.cached = true
+			constraints.forget()
+		}
 	}
 
 	algo := &Algo{
-		Func:       function,
-		Iterations: request.Iterations,
+		Func:        function,
+		Objective:   function,
+		Constraints: constraints,
+		Maximize:    maximize,
+		Objectives:  objectives,
+		Noise:       noise,
+		Batch:       batch,
+		Fit:         fit,
+		LocalSearch: localSearch,
+		Clock:       clock,
+
+		timeDependent: timeDependent,
+		Iterations:    request.Iterations,
+		TimeLimit:     timeLimit,
+		Stopping:      stopping,
+		Boundary:      boundary,
 
 		GlobalBestPosition: nil,
 		GlobalBestValue:    math.Inf(1),
+		bestAgent:          -1,
 	}
 
-	src := mt19937.New()
-	if request.Seed != nil {
-		src.Seed(int64(*request.Seed))
-	} else {
-		src.Seed(time.Now().UnixNano())
-	}
-	algo.Rng = rand.New(src)
+	algo.exactObjective = exactObjective
+
+	algo.source = newGenerator(seed)
+	algo.Rng = rand.New(algo.source)
 
 	if request.Bounds == nil {
 		if request.NumDimensions == nil {
 			algo.NumDimensions = len(request.Population[0])
+		} else {
+			algo.NumDimensions = *request.NumDimensions
 		}
 		algo.Bounds = make([][]float64, algo.NumDimensions)
 		for i := range algo.Bounds {
-			algo.Bounds[i] = []float64{-100, 100}
+			algo.Bounds[i] = []float64{defaultBound[0], defaultBound[1]}
 		}
 	} else if len(request.Bounds) > 0 {
 		algo.Bounds = request.Bounds
-		algo.NumDimensions = len(algo.Bounds[0])
+		algo.NumDimensions = len(algo.Bounds)
 	}
 
-	if request.Population == nil {
-		algo.PopulationSize = *request.PopulationSize
-		algo.Population = make([][]float64, algo.PopulationSize)
-		for i := range algo.PopulationSize {
-			algo.Population[i] = make([]float64, algo.NumDimensions)
-			for j := range algo.NumDimensions {
-				algo.Population[i][j] = algo.Rng.Float64()*(algo.Bounds[j][1]-algo.Bounds[j][0]) + algo.Bounds[j][0]
+	if err := algo.applyVariables(request.Variables); err != nil {
+		return nil, err
+	}
+
+	if movingPeaks {
+		var err error
+		peaks, err = NewMovingPeaks(setDefault(request.MovingPeaks, MovingPeaksRequest{}), algo.Bounds, clock, seed+2)
+		if err != nil {
+			return nil, err
+		}
+		algo.MovingPeaks = peaks
+	}
+
+	if request.ChangeDetection != nil {
+		var err error
+		algo.ChangeDetection, err = NewChangeDetection(*request.ChangeDetection)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if benchmark != nil && benchmark.Dimensions > 0 && benchmark.Dimensions != algo.NumDimensions {
+		return nil, fmt.Errorf("функция %s определена только для размерности %d", request.Benchmark, benchmark.Dimensions)
+	}
+	if native != nil && native.Dimensions > 0 && native.Dimensions != algo.NumDimensions {
+		return nil, fmt.Errorf("функция %s определена только для размерности %d", request.Native, native.Dimensions)
+	}
+
+	if requiredDimensions > algo.NumDimensions {
+		return nil, fmt.Errorf("функция использует %d переменных, а размерность задачи %d", requiredDimensions, algo.NumDimensions)
+	}
+
+	// оптимумы тестовых функций известны только для минимизации
+	var optimum func(n int) ([]float64, float64, bool)
+	switch {
+	case benchmark != nil:
+		optimum = benchmark.Optimum
+	case native != nil:
+		optimum = native.Optimum
+	}
+	var center []float64
+	var centerValue float64
+	knownOptimum := false
+	if optimum != nil && !maximize {
+		center, centerValue, knownOptimum = optimum(algo.NumDimensions)
+	}
+
+	if request.Transform != nil {
+		// поворот и сдвиг выполняются вокруг оптимума; у тестовой функции с неизвестным
+		// оптимумом они могли бы вынести его за границы поиска. Функции, заданные
+		// выражением, преобразуются вокруг начала координат.
+		testFunction := benchmark != nil || multiBenchmark != nil || movingPeaks || optimum != nil
+		if testFunction && !knownOptimum && (request.Transform.Rotate || request.Transform.RandomShift) {
+			return nil, errors.New("поворот и случайный сдвиг доступны только для функций с известным оптимумом")
+		}
+		var err error
+		if transform, err = NewTransform(*request.Transform, algo.Bounds, center, seed+3); err != nil {
+			return nil, err
+		}
+		algo.Transform = transform
+	}
+
+	if knownOptimum {
+		position, value := center, centerValue
+		if transform != nil {
+			position = transform.Inverse(position)
+			value += transform.Bias
+			for j, bound := range algo.Bounds {
+				if position[j] < bound[0] || position[j] > bound[1] {
+					return nil, errors.New("после сдвига оптимум функции оказался вне границ поиска")
+				}
 			}
 		}
+		algo.OptimumPosition = position
+		algo.OptimumValue = &value
+	}
+
+	if constraints != nil {
+		algo.Func = constraints.wrap(function)
+	}
+
+	// штраф за выход за границы входит во все критерии, по которым сравниваются агенты
+	if boundary.Method == PenaltyBoundary {
+		for i := range objectives {
+			objectives[i] = algo.penalize(objectives[i])
+		}
+		if objectives != nil {
+			algo.Func = objectives[0]
+		} else {
+			algo.Func = algo.penalize(algo.Func)
+		}
+	}
+
+	return algo, nil
+}
+
+// initPopulation создаёт и вычисляет начальную популяцию
+func (algo *Algo) initPopulation(request AlgoRequest) error {
+	initialization, err := NewInitialization(setDefault(request.Initialization, InitializationRequest{}), algo.Bounds)
+	if err != nil {
+		return err
+	}
+	if request.Population == nil && request.PopulationSize == nil && initialization.Population == nil {
+		return errors.New("недостаточно данных для создания популяции")
+	}
+	if request.Population != nil && initialization.Method != UniformInitialization {
+		return errors.New("способ создания популяции не применяется к заданной начальной популяции")
+	}
+
+	if request.Population == nil {
+		// без размера популяции продолжается популяция предыдущего запуска целиком
+		algo.PopulationSize = setDefault(request.PopulationSize, len(initialization.Population))
+		algo.Population = algo.samplePopulation(initialization, algo.PopulationSize)
 	} else {
 		algo.Population = request.Population
 		algo.PopulationSize = len(algo.Population)
+		if algo.Variables != nil {
+			for i, position := range algo.Population {
+				algo.Population[i] = algo.repair(slices.Clone(position))
+			}
+		}
 	}
 
-	for i := range algo.PopulationSize {
-		value := algo.Func(algo.Population[i])
+	if initialization.Method == OppositionInitialization {
+		// из равномерных точек и противоположных им остаются лучшие
+		candidates := algo.Population
+		for _, position := range algo.Population {
+			candidates = append(candidates, algo.opposite(position))
+		}
+		if algo.Constraints != nil {
+			algo.Constraints.initEpsilon(candidates)
+		}
+		algo.Population, algo.Fitness = fittest(candidates, algo.evaluatePopulation(candidates), algo.PopulationSize)
+	} else {
+		if algo.Constraints != nil {
+			algo.Constraints.initEpsilon(algo.Population)
+		}
+		algo.Fitness = algo.evaluatePopulation(algo.Population)
+	}
+	for i, value := range algo.Fitness {
 		if value < algo.GlobalBestValue {
-			algo.GlobalBestValue = value
-			algo.GlobalBestPosition = algo.Population[i]
+			algo.setBest(algo.Population[i], value, i)
 		}
 	}
+	if algo.GlobalBestPosition == nil && algo.Objectives == nil {
+		return errors.New("целевая функция не вычислена ни в одной точке начальной популяции")
+	}
 
-	return algo, nil
+	if algo.Objectives != nil {
+		algo.Archive = NewParetoArchive(setDefault(request.ArchiveSize, 100))
+		for _, position := range algo.Population {
+			algo.Archive.Add(position, algo.evaluateObjectives(position))
+		}
+	}
+
+	return nil
 }
 
-func ConvertMathExpressionToFunc(expression string) (func([]float64) float64, error) {
-	env := map[string]interface{}{
-		"x":    0.0,
-		"y":    0.0,
-		"sin":  math.Sin,
-		"cos":  math.Cos,
-		"tan":  math.Tan,
-		"PI":   math.Pi,
-		"log":  math.Log,
-		"ln":   math.Log,
-		"sqrt": math.Sqrt,
-		"abs":  math.Abs,
-		"pow":  math.Pow,
-		"exp":  math.Exp,
+// moveAgent перемещает агента в новую точку с уже вычисленным значением функции
+func (algo *Algo) moveAgent(i int, position []float64, value float64) {
+	algo.Population[i] = position
+	algo.Fitness[i] = value
+	if i == algo.bestAgent {
+		algo.bestAgent = -1
 	}
+}
 
-	program, err := expr.Compile(expression, expr.Env(env))
-	if err != nil {
-		return nil, err
+// setBest запоминает лучшее решение; agent — номер агента, стоящего в position,
+// или -1. Лучшее решение обновляется после перемещения агента, иначе moveAgent
+// сбросит номер.
+func (algo *Algo) setBest(position []float64, value float64, agent int) {
+	algo.GlobalBestPosition, algo.GlobalBestValue = position, value
+	algo.bestAgent = agent
+}
+
+// refreshFitness заново вычисляет значения функции для всей популяции; если от итерации
+// зависит только учёт ограничений, приспособленность пересчитывается по сохранённым значениям
+func (algo *Algo) refreshFitness() {
+	if algo.Constraints == nil || !algo.Constraints.cached || algo.changeDetected {
+		algo.Fitness = algo.evaluatePopulation(algo.Population)
+		return
+	}
+	for i, position := range algo.Population {
+		algo.Fitness[i] = algo.refit(position)
 	}
+}
 
-	return func(vars []float64) float64 {
-		if len(vars) != 2 {
-			fmt.Println("Ошибка: ожидался массив из 2 элементов [x, y]")
-			return math.NaN()
+// refit возвращает приспособленность точки по сохранённым значениям функции
+// и нарушения, а если их нет — вычисляет её заново
+func (algo *Algo) refit(position []float64) float64 {
+	if algo.Constraints != nil {
+		if value, violation, ok := algo.Constraints.lookup(position); ok {
+			return algo.Constraints.Fitness(value, violation) + algo.boundaryPenalty(position)
 		}
+	}
+	return algo.Func(position)
+}
 
-		env["x"] = vars[0]
-		env["y"] = vars[1]
+// evaluatePopulation вычисляет Func во всех точках; внешнему вычислителю
+// точки отправляются одним пакетом
+func (algo *Algo) evaluatePopulation(positions [][]float64) []float64 {
+	if algo.Batch == nil || algo.Noise != nil {
+		// шум берётся из общего генератора, пики сдвигаются по числу вычислений,
+		// а правила Деба запоминают худшее допустимое значение, поэтому для
+		// воспроизводимости такие функции вычисляются последовательно
+		sequential := algo.Noise != nil || algo.MovingPeaks != nil ||
+			(algo.Constraints != nil && (algo.Constraints.Method == FeasibilityRule || algo.Constraints.Method == EpsilonLevel))
+		if !sequential {
+			return evaluateParallel(algo.Func, positions)
+		}
+		values := make([]float64, len(positions))
+		for i, position := range positions {
+			values[i] = algo.Func(position)
+		}
+		return values
+	}
 
-		output, err := expr.Run(program, env)
-		if err != nil {
-			fmt.Println("Ошибка вычисления:", err)
-			return math.NaN()
+	granted := algo.Clock.reserve(len(positions))
+	values := append(algo.evaluateBatch(positions[:granted]), make([]float64, len(positions)-granted)...)
+	for i := granted; i < len(values); i++ {
+		values[i] = math.Inf(1)
+	}
+	for i, position := range positions[:granted] {
+		if algo.Maximize && !math.IsInf(values[i], 1) {
+			values[i] = -values[i]
+		}
+		if algo.Constraints != nil {
+			values[i] = algo.Constraints.evaluate(position, values[i])
 		}
+		values[i] += algo.boundaryPenalty(position)
+	}
+	return values
+}
 
-		if math.IsNaN(output.(float64)) {
-			return math.Inf(1)
+// evaluateBatch вычисляет целевую функцию внешним вычислителем одним пакетом
+// с учётом преобразования задачи, но без бюджета и смены знака
+func (algo *Algo) evaluateBatch(positions [][]float64) []float64 {
+	if algo.Transform == nil {
+		return algo.Batch.Evaluate(positions)
+	}
+	transformed := make([][]float64, len(positions))
+	for i, position := range positions {
+		transformed[i] = algo.Transform.Apply(position)
+	}
+	values := algo.Batch.Evaluate(transformed)
+	for i := range values {
+		values[i] += algo.Transform.Bias
+	}
+	return values
+}
+
+// Close завершает работу внешнего вычислителя, если он использовался
+func (algo *Algo) Close() error {
+	algo.finish()
+	if algo.Batch != nil {
+		return algo.Batch.Close()
+	}
+	return nil
+}
+
+func (algo *Algo) randomPosition() []float64 {
+	position := make([]float64, algo.NumDimensions)
+	for j := range algo.NumDimensions {
+		position[j] = algo.Rng.Float64()*(algo.Bounds[j][1]-algo.Bounds[j][0]) + algo.Bounds[j][0]
+	}
+	return algo.repair(position)
+}
+
+// negate меняет знак функции для максимизации; ошибка вычисления (+Inf)
+// остаётся худшим значением
+func negate(function func([]float64) float64) func([]float64) float64 {
+	return func(position []float64) float64 {
+		value := function(position)
+		if math.IsInf(value, 1) {
+			return value
 		}
-		return output.(float64)
-	}, nil
+		return -value
+	}
+}
+
+func (algo *Algo) evaluateObjectives(position []float64) []float64 {
+	values := make([]float64, len(algo.Objectives))
+	for i, objective := range algo.Objectives {
+		values[i] = objective(position)
+	}
+	return values
+}
+
+// addToArchive вычисляет критерии в новой точке и предлагает её архиву
+func (algo *Algo) addToArchive(position []float64) []float64 {
+	values := algo.evaluateObjectives(position)
+	// точка сверх бюджета вычислений не вычислялась
+	if math.IsInf(values[0], 1) && algo.Clock.Exhausted() {
+		return values
+	}
+	algo.Archive.Add(position, values)
+	return values
+}
+
+// improveBest обновляет лучшее решение многокритериальной задачи по первому
+// критерию; agent — номер агента, перешедшего в position, или -1
+func (algo *Algo) improveBest(position []float64, values []float64, agent int) {
+	if values[0] < algo.GlobalBestValue {
+		algo.setBest(slices.Clone(position), values[0], agent)
+	}
+}
+
+// setIteration сообщает номер текущей итерации функциям, зависящим от времени
+func (algo *Algo) setIteration(t int) {
+	algo.Clock.iteration.Store(int64(t))
+	algo.changeDetected = algo.detectChange()
+
+	if algo.Constraints != nil {
+		algo.Constraints.iteration = t
+	}
+	// сохранённые значения устарели: функция изменилась со временем
+	// или после её изменения часть популяции разбросана заново
+	changing := algo.timeDependent || (algo.Constraints != nil && algo.Constraints.changing())
+	if algo.changeDetected && algo.Constraints != nil {
+		// функция изменилась: сохранённые значения устарели
+		algo.Constraints.forget()
+	}
+	if changing && algo.GlobalBestPosition != nil {
+		algo.GlobalBestValue = algo.refit(algo.GlobalBestPosition)
+	}
+	algo.refreshed = changing || algo.changeDetected
+	if algo.refreshed {
+		algo.refreshFitness()
+		if algo.Constraints != nil {
+			algo.Constraints.age()
+		}
+	}
+
+	if algo.Noise != nil && algo.Noise.Resampling == EliteResampling && algo.GlobalBestPosition != nil {
+		algo.GlobalBestValue = algo.Noise.reevaluate(algo.Func, algo.GlobalBestPosition, algo.GlobalBestValue)
+		// агент в лучшем решении тоже получает среднее, иначе при сравнении
+		// с ним вернулось бы значение единственного удачного вычисления
+		if algo.bestAgent >= 0 {
+			algo.Fitness[algo.bestAgent] = algo.GlobalBestValue
+		}
+	}
+
+	if algo.LocalSearch != nil && algo.LocalSearch.Every > 0 && t > 0 && t%algo.LocalSearch.Every == 0 {
+		algo.refine()
+	}
+}
+
+func (algo *Algo) newResponse(stepPositions [][]float64, iteration int) Response {
+	if algo.LocalSearch != nil && iteration > 0 && algo.isFinal(iteration) {
+		algo.refine()
+	}
+
+	response := Response{
+		StepPositions: stepPositions,
+		BestPosition:  algo.GlobalBestPosition,
+		BestLabels:    algo.labels(algo.GlobalBestPosition),
+		BestValue:     algo.GlobalBestValue,
+		Iteration:     iteration,
+		Evaluations:   algo.Clock.Evaluations(),
+	}
+
+	// с ограничениями GlobalBestValue недопустимого решения включает штраф,
+	// поэтому отдаём само значение функции
+	if algo.Constraints != nil && algo.GlobalBestPosition != nil {
+		violation := algo.Constraints.Violation(algo.GlobalBestPosition)
+		feasible := violation == 0
+		if !feasible {
+			response.BestValue = algo.exactObjective(algo.GlobalBestPosition)
+		}
+		response.Violation = &violation
+		response.Feasible = &feasible
+	}
+
+	// при шуме точность оценивается по значению функции без шума
+	exactValue := response.BestValue
+	if algo.Noise != nil && algo.GlobalBestPosition != nil {
+		exactValue = algo.exactObjective(algo.GlobalBestPosition)
+		exactBestValue := algo.userValue(exactValue)
+		response.ExactBestValue = &exactBestValue
+	}
+
+	if algo.MovingPeaks != nil && !algo.Maximize {
+		position, value := algo.MovingPeaks.Optimum()
+		algo.OptimumPosition, algo.OptimumValue = position, &value
+	}
+	response.ChangeDetected = algo.changeDetected
+	if algo.Batch != nil {
+		if err := algo.Batch.Err(); err != nil {
+			response.Error = err.Error()
+		}
+	}
+
+	if algo.OptimumValue != nil {
+		optimumError := math.Abs(exactValue - *algo.OptimumValue)
+		response.OptimumError = &optimumError
+	}
+
+	if algo.Archive != nil {
+		response.ParetoFront = algo.Archive.Front()
+		for _, values := range response.ParetoFront.Values {
+			for i := range values {
+				values[i] = algo.userValue(values[i])
+			}
+		}
+	}
+
+	if algo.Fit != nil && algo.GlobalBestPosition != nil && algo.isFinal(iteration) {
+		parameters := algo.GlobalBestPosition
+		if algo.Transform != nil {
+			parameters = algo.Transform.Apply(parameters)
+		}
+		response.Fit = algo.Fit.Result(parameters)
+	}
+
+	response.BestValue = algo.userValue(response.BestValue)
+	if algo.isFinal(iteration) {
+		response.StopReason = algo.stopReason(iteration)
+		response.Summary = summary(response.StopReason, response)
+	}
+	return response
+}
+
+// start связывает работу алгоритма с контекстом и ограничением времени;
+// возвращённую функцию Run вызывает по окончании работы
+func (algo *Algo) start(ctx context.Context) context.CancelFunc {
+	cancel := context.CancelFunc(func() {})
+	if algo.TimeLimit > 0 {
+		ctx, cancel = context.WithTimeout(ctx, algo.TimeLimit)
+	}
+	algo.Clock.ctx = ctx
+	return cancel
+}
+
+// begin связывает алгоритм с контекстом и формирует ответ с начальной популяцией
+func (algo *Algo) begin(ctx context.Context) Response {
+	algo.finish()
+	algo.cancel = algo.start(ctx)
+	algo.next = 0
+	algo.History, algo.stopped = nil, ""
+	algo.checkStopping()
+	algo.state = algo.newResponse(algo.Population, 0)
+	return algo.state
+}
+
+// advance выполняет очередную итерацию алгоритма, если он ещё не завершил работу
+func (algo *Algo) advance(iterate func(t int) [][]float64) (Response, bool) {
+	if algo.next >= algo.Iterations || algo.done() {
+		algo.finish()
+		return algo.state, false
+	}
+	t := algo.next
+	algo.setIteration(t)
+	positions := iterate(t)
+	algo.next++
+	algo.checkStopping()
+	algo.state = algo.newResponse(positions, t+1)
+	return algo.state, true
+}
+
+// finish освобождает таймер ограничения времени
+func (algo *Algo) finish() {
+	if algo.cancel != nil {
+		algo.cancel()
+		algo.cancel = nil
+	}
+}
+
+func (algo *Algo) State() Response {
+	return algo.state
+}
+
+// drive отправляет начальный ответ и ответы всех итераций, пока send не вернёт ошибку
+func (algo *Algo) drive(initial Response, step func() (Response, bool), send func(Response) error) ([]float64, float64) {
+	defer algo.finish()
+
+	if err := send(initial); err != nil {
+		return algo.result()
+	}
+	for {
+		response, ok := step()
+		if !ok {
+			break
+		}
+		if err := send(response); err != nil {
+			break
+		}
+	}
+	return algo.result()
+}
+
+// done проверяет перед очередной итерацией или очередным вычислением,
+// что алгоритм пора остановить
+func (algo *Algo) done() bool {
+	return algo.Clock.Exhausted() || algo.stopped != ""
+}
+
+// isFinal проверяет, что ответ на этой итерации — последний
+func (algo *Algo) isFinal(iteration int) bool {
+	return iteration == algo.Iterations || algo.done()
+}
+
+// result возвращает лучшее решение со значением в исходном знаке целевой функции
+func (algo *Algo) result() ([]float64, float64) {
+	return algo.GlobalBestPosition, algo.userValue(algo.GlobalBestValue)
+}
+
+func (algo *Algo) userValue(value float64) float64 {
+	if algo.Maximize {
+		return -value
+	}
+	return value
 }
@@ -1,6 +1,7 @@
 package algos
 
 import (
+	"context"
 	"math"
 )
 
@@ -21,6 +22,10 @@ type FA struct {
 }
 
 func NewFA(request FARequest) (Algorithm, error) {
+	return newFA(request)
+}
+
+func newFA(request FARequest) (*FA, error) {
 	algo, err := NewAlgo(request.AlgoRequest)
 	if err != nil {
 		return nil, err
@@ -34,59 +39,75 @@ func NewFA(request FARequest) (Algorithm, error) {
 	}, nil
 }
 
-func (fa *FA) Run(send func(Response) error) ([]float64, float64) {
-	err := send(Response{
-		StepPositions: fa.Population,
-		BestPosition:  fa.GlobalBestPosition,
-		BestValue:     fa.GlobalBestValue,
-		Iteration:     0,
-	})
-	if err != nil {
-		return fa.GlobalBestPosition, fa.GlobalBestValue
+func (fa *FA) Init(ctx context.Context) Response {
+	return fa.begin(ctx)
+}
+
+func (fa *FA) Checkpoint() *Snapshot {
+	return fa.checkpoint("FA")
+}
+
+func (fa *FA) Resume(ctx context.Context, snapshot *Snapshot) (Response, error) {
+	if err := fa.restore(snapshot, "FA"); err != nil {
+		return Response{}, err
 	}
+	return fa.resume(ctx), nil
+}
 
-	for t := range fa.Iterations {
-		for i := range fa.PopulationSize {
-			maxDistance := 0.0
-			for j := range fa.PopulationSize {
-				if i != j {
-					// расстояние между светлячками i и j
-					rij := 0.0
-					for k := range fa.NumDimensions {
-						rij += math.Pow(fa.Population[i][k]-fa.Population[j][k], 2)
-					}
-					rij = math.Sqrt(rij)
-					if rij > maxDistance {
-						maxDistance = rij
-					}
-				}
-			}
+func (fa *FA) Step() (Response, bool) {
+	return fa.advance(fa.iterate)
+}
 
-			for j := range fa.PopulationSize {
-				if i == j {
-					continue
-				}
-				if fa.Func(fa.Population[j]) < fa.Func(fa.Population[i]) {
-					fa.Population[i] = fa.UpdatePosition(fa.Population[i], fa.Population[j], maxDistance)
-				}
+func (fa *FA) Run(ctx context.Context, send func(Response) error) ([]float64, float64) {
+	return fa.drive(fa.Init(ctx), fa.Step, send)
+}
+
+// iterate выполняет итерацию t и возвращает положения агентов для ответа
+func (fa *FA) iterate(t int) [][]float64 {
+	for i := range fa.PopulationSize {
+		maxDistance := fa.maxDistance(i)
+
+		for j := range fa.PopulationSize {
+			if i == j {
+				continue
+			}
+			if fa.done() {
+				break
 			}
-			if fa.Func(fa.Population[i]) < fa.GlobalBestValue {
-				fa.GlobalBestValue = fa.Func(fa.Population[i])
-				fa.GlobalBestPosition = fa.Population[i]
+			if fa.Fitness[j] < fa.Fitness[i] {
+				position := fa.UpdatePosition(fa.Population[i], fa.Population[j], maxDistance)
+				value := fa.Func(position)
+				// вычисление отменено — светлячок остаётся на месте
+				if math.IsInf(value, 1) && fa.done() {
+					break
+				}
+				fa.moveAgent(i, position, value)
 			}
 		}
+		if fa.Fitness[i] < fa.GlobalBestValue {
+			fa.setBest(fa.Population[i], fa.Fitness[i], i)
+		}
+	}
+
+	return fa.Population
+}
 
-		err = send(Response{
-			StepPositions: fa.Population,
-			BestPosition:  fa.GlobalBestPosition,
-			BestValue:     fa.GlobalBestValue,
-			Iteration:     t + 1,
-		})
-		if err != nil {
-			return fa.GlobalBestPosition, fa.GlobalBestValue
+func (fa *FA) maxDistance(i int) float64 {
+	maxDistance := 0.0
+	for j := range fa.PopulationSize {
+		if i != j {
+			// расстояние между светлячками i и j
+			rij := 0.0
+			for k := range fa.NumDimensions {
+				rij += math.Pow(fa.Population[i][k]-fa.Population[j][k], 2)
+			}
+			rij = math.Sqrt(rij)
+			if rij > maxDistance {
+				maxDistance = rij
+			}
 		}
 	}
-	return fa.GlobalBestPosition, fa.GlobalBestValue
+	return maxDistance
 }
 
 func (fa *FA) UpdatePosition(xi, xj []float64, maxDistance float64) []float64 {
@@ -100,10 +121,9 @@ func (fa *FA) UpdatePosition(xi, xj []float64, maxDistance float64) []float64 {
 		directedComponent := fa.Beta0 * math.Exp(-gamma_i*rij*rij) * (xj[k] - xi[k])
 
 		newPosition[k] = xi[k] + directedComponent + fa.Alpha*(randValue-0.5)
-		newPosition[k] = math.Max(fa.Bounds[k][0], math.Min(fa.Bounds[k][1], newPosition[k]))
 	}
 
-	return newPosition
+	return fa.confine(newPosition, xi)
 }
 
 func (fa *FA) calculateDistance(a, b []float64) float64 {
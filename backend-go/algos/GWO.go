@@ -1,8 +1,10 @@
 package algos
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"math"
+	"slices"
 )
 
 type GWORequest struct {
@@ -23,6 +25,10 @@ type GWO struct {
 }
 
 func NewGWO(request GWORequest) (Algorithm, error) {
+	return newGWO(request)
+}
+
+func newGWO(request GWORequest) (*GWO, error) {
 	algo, err := NewAlgo(request.AlgoRequest)
 	if err != nil {
 		return nil, err
@@ -39,72 +45,122 @@ func NewGWO(request GWORequest) (Algorithm, error) {
 	}, nil
 }
 
-func (gwo *GWO) Run(send func(Response) error) ([]float64, float64) {
+func (gwo *GWO) Init(ctx context.Context) Response {
 	gwo.updateBestWolves()
-	err := send(Response{
-		StepPositions: gwo.Population,
-		BestPosition:  gwo.GlobalBestPosition,
-		BestValue:     gwo.GlobalBestValue,
-		Iteration:     0,
-	})
+	return gwo.begin(ctx)
+}
 
-	if err != nil {
-		fmt.Println("CONN ERR", err.Error())
-		return gwo.GlobalBestPosition, gwo.GlobalBestValue
+// GWOState — бета- и дельта-волки в снимке
+type GWOState struct {
+	Beta       []float64
+	BetaValue  float64
+	Delta      []float64
+	DeltaValue float64
+}
+
+func (gwo *GWO) Checkpoint() *Snapshot {
+	snapshot := gwo.checkpoint("GWO")
+	snapshot.GWO = gwo.wolves()
+	return snapshot
+}
+
+func (gwo *GWO) Resume(ctx context.Context, snapshot *Snapshot) (Response, error) {
+	if err := gwo.restoreWolves(snapshot, "GWO"); err != nil {
+		return Response{}, err
 	}
-	for t := range gwo.Iterations {
-		a := gwo.a - (gwo.a*float64(t))/float64(gwo.Iterations)
-
-		for i, w := range gwo.Population {
-			Xnew := make([]float64, gwo.NumDimensions)
-			copy(Xnew, w)
-			for j := range gwo.NumDimensions {
-				X1 := gwo.hunting(gwo.GlobalBestPosition[j], w[j], a)
-				X2 := gwo.hunting(gwo.beta[j], w[j], a)
-				X3 := gwo.hunting(gwo.delta[j], w[j], a)
-
-				Xnew[j] = (X1 + X2 + X3) / 3
-				Xnew[j] = math.Max(gwo.Bounds[j][0], math.Min(Xnew[j], gwo.Bounds[j][1]))
-			}
-			if gwo.Func(Xnew) < gwo.Func(w) {
-				gwo.Population[i] = Xnew
-			}
+	return gwo.resume(ctx), nil
+}
+
+func (gwo *GWO) wolves() *GWOState {
+	return &GWOState{
+		Beta:       slices.Clone(gwo.beta),
+		BetaValue:  gwo.betaValue,
+		Delta:      slices.Clone(gwo.delta),
+		DeltaValue: gwo.deltaValue,
+	}
+}
 
+func (gwo *GWO) restoreWolves(snapshot *Snapshot, name string) error {
+	if snapshot.GWO == nil {
+		return errors.New("в снимке нет состояния волков")
+	}
+	if err := gwo.restore(snapshot, name); err != nil {
+		return err
+	}
+	gwo.beta, gwo.betaValue = slices.Clone(snapshot.GWO.Beta), snapshot.GWO.BetaValue
+	gwo.delta, gwo.deltaValue = slices.Clone(snapshot.GWO.Delta), snapshot.GWO.DeltaValue
+	return nil
+}
+
+func (gwo *GWO) Step() (Response, bool) {
+	return gwo.advance(gwo.iterate)
+}
+
+func (gwo *GWO) Run(ctx context.Context, send func(Response) error) ([]float64, float64) {
+	return gwo.drive(gwo.Init(ctx), gwo.Step, send)
+}
+
+// iterate выполняет итерацию t и возвращает положения агентов для ответа
+func (gwo *GWO) iterate(t int) [][]float64 {
+	if gwo.refreshed {
+		gwo.refreshWolves()
+	}
+	a := gwo.a - (gwo.a*float64(t))/float64(gwo.Iterations)
+
+	// вожаки не меняются до конца итерации, поэтому новые положения
+	// всей стаи вычисляются одним пакетом
+	candidates := make([][]float64, gwo.PopulationSize)
+	for i, w := range gwo.Population {
+		Xnew := make([]float64, gwo.NumDimensions)
+		copy(Xnew, w)
+		for j := range gwo.NumDimensions {
+			X1 := gwo.hunting(gwo.GlobalBestPosition[j], w[j], a)
+			X2 := gwo.hunting(gwo.beta[j], w[j], a)
+			X3 := gwo.hunting(gwo.delta[j], w[j], a)
+
+			Xnew[j] = (X1 + X2 + X3) / 3
 		}
-		gwo.updateBestWolves()
-
-		err := send(Response{
-			StepPositions: gwo.Population,
-			BestPosition:  gwo.GlobalBestPosition,
-			BestValue:     gwo.GlobalBestValue,
-			Iteration:     t + 1,
-		})
-		if err != nil {
-			fmt.Println("CONN ERR", err.Error())
-			return gwo.GlobalBestPosition, gwo.GlobalBestValue
+		candidates[i] = gwo.confine(Xnew, w)
+	}
+	values := gwo.evaluatePopulation(candidates)
+	for i, Xnew := range candidates {
+		if values[i] < gwo.Fitness[i] {
+			gwo.moveAgent(i, Xnew, values[i])
 		}
 	}
+	gwo.updateBestWolves()
 
-	return gwo.GlobalBestPosition, gwo.GlobalBestValue
+	return gwo.Population
 }
 
 func (gwo *GWO) updateBestWolves() {
-	for _, wolf := range gwo.Population {
-		value := gwo.Func(wolf)
+	for i, wolf := range gwo.Population {
+		value := gwo.Fitness[i]
 		if value < gwo.GlobalBestValue {
 			gwo.delta, gwo.deltaValue = gwo.beta, gwo.betaValue
 			gwo.beta, gwo.betaValue = gwo.GlobalBestPosition, gwo.GlobalBestValue
-			gwo.GlobalBestPosition, gwo.GlobalBestValue = wolf, value
+			gwo.setBest(wolf, value, i)
 		} else if value < gwo.betaValue {
 			gwo.delta, gwo.deltaValue = gwo.beta, gwo.betaValue
 			gwo.beta, gwo.betaValue = gwo.GlobalBestPosition, gwo.GlobalBestValue
-			gwo.GlobalBestPosition, gwo.GlobalBestValue = wolf, value
+			gwo.setBest(wolf, value, i)
 		} else if value < gwo.deltaValue {
 			gwo.delta, gwo.deltaValue = wolf, value
 		}
 	}
 }
 
+// refreshWolves перевычисляет бета- и дельта-волков после изменения функции;
+// значение альфы уже обновлено в setIteration
+func (gwo *GWO) refreshWolves() {
+	if gwo.beta != nil {
+		gwo.betaValue = gwo.refit(gwo.beta)
+	}
+	if gwo.delta != nil {
+		gwo.deltaValue = gwo.refit(gwo.delta)
+	}
+}
+
 func (gwo *GWO) hunting(prey, wolf, a float64) float64 {
 	r1, r2 := gwo.Rng.Float64(), gwo.Rng.Float64()
 	A := a * (2*r1 - 1)
@@ -0,0 +1,135 @@
+package algos
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// MOABC — многокритериальная пчелиная колония: источники сравниваются по
+// доминированию, наблюдатели улучшают источники в сторону лидеров из архива.
+type MOABC struct {
+	ABC
+
+	values [][]float64
+}
+
+func NewMOABC(request ABCRequest) (Algorithm, error) {
+	request.multiObjective = true
+	abc, err := newABC(request)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]float64, abc.PopulationSize)
+	for i, position := range abc.Population {
+		values[i] = abc.evaluateObjectives(position)
+	}
+
+	return &MOABC{
+		ABC:    *abc,
+		values: values,
+	}, nil
+}
+
+func (moabc *MOABC) Init(ctx context.Context) Response {
+	return moabc.begin(ctx)
+}
+
+func (moabc *MOABC) Checkpoint() *Snapshot {
+	snapshot := moabc.checkpoint("MOABC")
+	snapshot.ABC = &ABCState{Trials: slices.Clone(moabc.Trials)}
+	snapshot.Values = cloneMatrix(moabc.values)
+	return snapshot
+}
+
+func (moabc *MOABC) Resume(ctx context.Context, snapshot *Snapshot) (Response, error) {
+	if len(snapshot.Values) != moabc.PopulationSize {
+		return Response{}, errors.New("в снимке нет значений критериев агентов")
+	}
+	if err := moabc.restoreTrials(snapshot, "MOABC"); err != nil {
+		return Response{}, err
+	}
+	moabc.values = cloneMatrix(snapshot.Values)
+	return moabc.resume(ctx), nil
+}
+
+func (moabc *MOABC) Step() (Response, bool) {
+	return moabc.advance(moabc.iterate)
+}
+
+func (moabc *MOABC) Run(ctx context.Context, send func(Response) error) ([]float64, float64) {
+	return moabc.drive(moabc.Init(ctx), moabc.Step, send)
+}
+
+// iterate выполняет итерацию t и возвращает положения агентов для ответа
+func (moabc *MOABC) iterate(t int) [][]float64 {
+	moabc.foragerPhase()
+	moabc.observerPhase()
+	moabc.scoutPhase()
+
+	return moabc.Population
+}
+
+func (moabc *MOABC) foragerPhase() {
+	for i := range moabc.ForagerSize {
+		if moabc.done() {
+			return
+		}
+		k := moabc.Rng.Intn(moabc.ForagerSize) // другой собиратель
+		for k == i {
+			k = moabc.Rng.Intn(moabc.ForagerSize)
+		}
+		moabc.tryReplace(i, moabc.mutate(moabc.Population[i], moabc.Population[k]))
+	}
+}
+
+func (moabc *MOABC) observerPhase() {
+	for range moabc.ObserverSize {
+		if moabc.done() {
+			return
+		}
+		j := moabc.selectForager()
+		leader := moabc.Archive.SelectLeader(moabc.Rng)
+		moabc.tryReplace(j, moabc.mutate(moabc.Population[j], leader))
+	}
+}
+
+func (moabc *MOABC) scoutPhase() {
+	for i := range moabc.ForagerSize {
+		if moabc.done() {
+			return
+		}
+		if moabc.Trials[i] > moabc.Limit {
+			position := moabc.randomSolution()
+			moabc.values[i] = moabc.addToArchive(position)
+			moabc.moveAgent(i, position, moabc.values[i][0])
+			moabc.improveBest(position, moabc.values[i], i)
+			moabc.Trials[i] = 0
+		}
+	}
+}
+
+// tryReplace заменяет источник, если новое решение его доминирует,
+// а при взаимной недоминируемости — с вероятностью 1/2
+func (moabc *MOABC) tryReplace(i int, candidate []float64) {
+	values := moabc.addToArchive(candidate)
+	if dominates(values, moabc.values[i]) || (!dominates(moabc.values[i], values) && moabc.Rng.Float64() < 0.5) {
+		moabc.moveAgent(i, candidate, values[0])
+		moabc.values[i] = values
+		moabc.Trials[i] = 0
+		moabc.improveBest(candidate, values, i)
+	} else {
+		moabc.Trials[i]++
+		moabc.improveBest(candidate, values, -1)
+	}
+}
+
+// selectForager выбирает собирателя бинарным турниром по доминированию
+func (moabc *MOABC) selectForager() int {
+	i, j := moabc.Rng.Intn(moabc.ForagerSize), moabc.Rng.Intn(moabc.ForagerSize)
+	if dominates(moabc.values[j], moabc.values[i]) {
+		return j
+	}
+	return i
+}
@@ -0,0 +1,100 @@
+package algos
+
+import (
+	"context"
+	"errors"
+)
+
+// MOFA — многокритериальный алгоритм светлячков (Yang, 2013): светлячок летит
+// к доминирующим его соседям, а недоминируемый — к лидеру из архива.
+type MOFA struct {
+	FA
+
+	values [][]float64
+}
+
+func NewMOFA(request FARequest) (Algorithm, error) {
+	request.multiObjective = true
+	fa, err := newFA(request)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]float64, fa.PopulationSize)
+	for i, position := range fa.Population {
+		values[i] = fa.evaluateObjectives(position)
+	}
+
+	return &MOFA{
+		FA:     *fa,
+		values: values,
+	}, nil
+}
+
+func (mofa *MOFA) Init(ctx context.Context) Response {
+	return mofa.begin(ctx)
+}
+
+func (mofa *MOFA) Checkpoint() *Snapshot {
+	snapshot := mofa.checkpoint("MOFA")
+	snapshot.Values = cloneMatrix(mofa.values)
+	return snapshot
+}
+
+func (mofa *MOFA) Resume(ctx context.Context, snapshot *Snapshot) (Response, error) {
+	if len(snapshot.Values) != mofa.PopulationSize {
+		return Response{}, errors.New("в снимке нет значений критериев агентов")
+	}
+	if err := mofa.restore(snapshot, "MOFA"); err != nil {
+		return Response{}, err
+	}
+	mofa.values = cloneMatrix(snapshot.Values)
+	return mofa.resume(ctx), nil
+}
+
+func (mofa *MOFA) Step() (Response, bool) {
+	return mofa.advance(mofa.iterate)
+}
+
+func (mofa *MOFA) Run(ctx context.Context, send func(Response) error) ([]float64, float64) {
+	return mofa.drive(mofa.Init(ctx), mofa.Step, send)
+}
+
+// iterate выполняет итерацию t и возвращает положения агентов для ответа
+func (mofa *MOFA) iterate(t int) [][]float64 {
+	for i := range mofa.PopulationSize {
+		if mofa.done() {
+			break
+		}
+		maxDistance := mofa.maxDistance(i)
+
+		dominated := false
+		for j := range mofa.PopulationSize {
+			if i == j || !dominates(mofa.values[j], mofa.values[i]) {
+				continue
+			}
+			dominated = true
+			// каждый перелёт — отдельное вычисление: бюджет может кончиться
+			// посреди перелётов одного светлячка
+			if mofa.done() {
+				break
+			}
+			mofa.fly(i, mofa.Population[j], maxDistance)
+		}
+
+		if !dominated {
+			leader := mofa.Archive.SelectLeader(mofa.Rng)
+			mofa.fly(i, leader, maxDistance)
+		}
+	}
+
+	return mofa.Population
+}
+
+// fly перемещает светлячка i к светлячку или лидеру target
+func (mofa *MOFA) fly(i int, target []float64, maxDistance float64) {
+	position := mofa.UpdatePosition(mofa.Population[i], target, maxDistance)
+	mofa.values[i] = mofa.addToArchive(position)
+	mofa.moveAgent(i, position, mofa.values[i][0])
+	mofa.improveBest(position, mofa.values[i], i)
+}
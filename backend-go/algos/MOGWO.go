@@ -0,0 +1,76 @@
+package algos
+
+import (
+	"context"
+)
+
+// MOGWO — многокритериальный GWO (Mirjalili и др., 2016): альфа, бета и дельта
+// выбираются из архива недоминируемых решений.
+type MOGWO struct {
+	GWO
+}
+
+func NewMOGWO(request GWORequest) (Algorithm, error) {
+	request.multiObjective = true
+	gwo, err := newGWO(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MOGWO{GWO: *gwo}, nil
+}
+
+func (mogwo *MOGWO) Init(ctx context.Context) Response {
+	return mogwo.begin(ctx)
+}
+
+func (mogwo *MOGWO) Checkpoint() *Snapshot {
+	snapshot := mogwo.checkpoint("MOGWO")
+	snapshot.GWO = mogwo.wolves()
+	return snapshot
+}
+
+func (mogwo *MOGWO) Resume(ctx context.Context, snapshot *Snapshot) (Response, error) {
+	if err := mogwo.restoreWolves(snapshot, "MOGWO"); err != nil {
+		return Response{}, err
+	}
+	return mogwo.resume(ctx), nil
+}
+
+func (mogwo *MOGWO) Step() (Response, bool) {
+	return mogwo.advance(mogwo.iterate)
+}
+
+func (mogwo *MOGWO) Run(ctx context.Context, send func(Response) error) ([]float64, float64) {
+	return mogwo.drive(mogwo.Init(ctx), mogwo.Step, send)
+}
+
+// iterate выполняет итерацию t и возвращает положения агентов для ответа
+func (mogwo *MOGWO) iterate(t int) [][]float64 {
+	a := mogwo.a - (mogwo.a*float64(t))/float64(mogwo.Iterations)
+
+	for i, w := range mogwo.Population {
+		if mogwo.done() {
+			break
+		}
+		alpha := mogwo.Archive.SelectLeader(mogwo.Rng)
+		beta := mogwo.Archive.SelectLeader(mogwo.Rng)
+		delta := mogwo.Archive.SelectLeader(mogwo.Rng)
+
+		Xnew := make([]float64, mogwo.NumDimensions)
+		for j := range mogwo.NumDimensions {
+			X1 := mogwo.hunting(alpha[j], w[j], a)
+			X2 := mogwo.hunting(beta[j], w[j], a)
+			X3 := mogwo.hunting(delta[j], w[j], a)
+
+			Xnew[j] = (X1 + X2 + X3) / 3
+		}
+		mogwo.confine(Xnew, w)
+
+		values := mogwo.addToArchive(Xnew)
+		mogwo.moveAgent(i, Xnew, values[0])
+		mogwo.improveBest(Xnew, values, i)
+	}
+
+	return mogwo.Population
+}
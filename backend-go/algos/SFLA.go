@@ -1,7 +1,9 @@
 package algos
 
 import (
+	"context"
 	"math"
+	"slices"
 )
 
 type SFLARequest struct {
@@ -32,41 +34,47 @@ func NewSFLA(request SFLARequest) (Algorithm, error) {
 	}, nil
 }
 
-func (sfla *SFLA) Run(send func(Response) error) ([]float64, float64) {
-	err := send(Response{
-		StepPositions: sfla.Population,
-		BestPosition:  sfla.GlobalBestPosition,
-		BestValue:     sfla.GlobalBestValue,
-		Iteration:     0,
-	})
-	if err != nil {
-		return sfla.GlobalBestPosition, sfla.GlobalBestValue
+func (sfla *SFLA) Init(ctx context.Context) Response {
+	return sfla.begin(ctx)
+}
+
+func (sfla *SFLA) Checkpoint() *Snapshot {
+	return sfla.checkpoint("SFLA")
+}
+
+func (sfla *SFLA) Resume(ctx context.Context, snapshot *Snapshot) (Response, error) {
+	if err := sfla.restore(snapshot, "SFLA"); err != nil {
+		return Response{}, err
 	}
+	return sfla.resume(ctx), nil
+}
 
-	for t := range sfla.Iterations {
-		for i := range sfla.SubpopulationsCount {
-			sfla.localSearch(i)
-			sfla.updateBest()
-		}
+func (sfla *SFLA) Step() (Response, bool) {
+	return sfla.advance(sfla.iterate)
+}
 
-		sfla.shufflePopulation()
+func (sfla *SFLA) Run(ctx context.Context, send func(Response) error) ([]float64, float64) {
+	return sfla.drive(sfla.Init(ctx), sfla.Step, send)
+}
 
-		err = send(Response{
-			StepPositions: sfla.Population,
-			BestPosition:  sfla.GlobalBestPosition,
-			BestValue:     sfla.GlobalBestValue,
-			Iteration:     (t + 1),
-		})
-		if err != nil {
-			return sfla.GlobalBestPosition, sfla.GlobalBestValue
-		}
+// iterate выполняет итерацию t и возвращает положения агентов для ответа
+func (sfla *SFLA) iterate(t int) [][]float64 {
+	for i := range sfla.SubpopulationsCount {
+		sfla.localSearch(i)
+		sfla.updateBest()
 	}
 
-	return sfla.GlobalBestPosition, sfla.GlobalBestValue
+	sfla.shufflePopulation()
+
+	return sfla.Population
 }
 
 func (sfla *SFLA) localSearch(i int) {
 	for range sfla.IMax {
+		// без бюджета вычислений лучшую и худшую лягушку не определить
+		if sfla.done() {
+			return
+		}
 		subpopSize := sfla.PopulationSize / sfla.SubpopulationsCount
 		subpopStart := i * subpopSize
 		subpopEnd := (i + 1) * subpopSize
@@ -77,7 +85,7 @@ func (sfla *SFLA) localSearch(i int) {
 		worstInSubpopValue := -math.Inf(1)
 
 		for j := subpopStart; j < subpopEnd; j++ {
-			value := sfla.Func(sfla.Population[j])
+			value := sfla.Fitness[j]
 			if value < bestInSubpopValue {
 				bestInSubpopValue = value
 				bestInSubpopIndex = j
@@ -88,35 +96,40 @@ func (sfla *SFLA) localSearch(i int) {
 			}
 		}
 
+		// худшая лягушка прыгает в новую точку, а не сдвигается на месте:
+		// на её прежнее положение может ссылаться GlobalBestPosition
+		frog := slices.Clone(sfla.Population[worstInSubpopIndex])
 		r := sfla.Rng.Float64()
 		for d := range sfla.NumDimensions {
-			sfla.Population[worstInSubpopIndex][d] += r * (sfla.Population[bestInSubpopIndex][d] - sfla.Population[worstInSubpopIndex][d])
-			sfla.Population[worstInSubpopIndex][d] = math.Max(sfla.Bounds[d][0], math.Min(sfla.Population[worstInSubpopIndex][d], sfla.Bounds[d][1]))
+			frog[d] += r * (sfla.Population[bestInSubpopIndex][d] - frog[d])
 		}
+		sfla.confine(frog, sfla.Population[worstInSubpopIndex])
 
-		if sfla.Func(sfla.Population[worstInSubpopIndex]) >= worstInSubpopValue {
+		value := sfla.Func(frog)
+		if value >= worstInSubpopValue {
 			r = sfla.Rng.Float64()
+			previous := slices.Clone(frog)
 			for d := range sfla.NumDimensions {
-				sfla.Population[worstInSubpopIndex][d] += r * (sfla.GlobalBestPosition[d] - sfla.Population[worstInSubpopIndex][d])
-				sfla.Population[worstInSubpopIndex][d] = math.Max(sfla.Bounds[d][0], math.Min(sfla.Population[worstInSubpopIndex][d], sfla.Bounds[d][1]))
+				frog[d] += r * (sfla.GlobalBestPosition[d] - frog[d])
 			}
+			sfla.confine(frog, previous)
 
-			if sfla.Func(sfla.Population[worstInSubpopIndex]) >= worstInSubpopValue {
-				for d := range sfla.NumDimensions {
-					sfla.Population[worstInSubpopIndex][d] = sfla.Rng.Float64()*(sfla.Bounds[d][1]-sfla.Bounds[d][0]) + sfla.Bounds[d][0]
-				}
+			value = sfla.Func(frog)
+			if value >= worstInSubpopValue {
+				frog = sfla.randomPosition()
+				value = sfla.Func(frog)
 			}
 		}
+		sfla.moveAgent(worstInSubpopIndex, frog, value)
 
 	}
 }
 
 func (sfla *SFLA) updateBest() {
-	for _, frog := range sfla.Population {
-		value := sfla.Func(frog)
+	for i, frog := range sfla.Population {
+		value := sfla.Fitness[i]
 		if value < sfla.GlobalBestValue {
-			sfla.GlobalBestValue = value
-			sfla.GlobalBestPosition = frog
+			sfla.setBest(frog, value, i)
 		}
 	}
 }
@@ -124,5 +137,12 @@ func (sfla *SFLA) updateBest() {
 func (sfla *SFLA) shufflePopulation() {
 	sfla.Rng.Shuffle(sfla.PopulationSize, func(i, j int) {
 		sfla.Population[i], sfla.Population[j] = sfla.Population[j], sfla.Population[i]
+		sfla.Fitness[i], sfla.Fitness[j] = sfla.Fitness[j], sfla.Fitness[i]
+		switch sfla.bestAgent {
+		case i:
+			sfla.bestAgent = j
+		case j:
+			sfla.bestAgent = i
+		}
 	})
 }
@@ -0,0 +1,33 @@
+package algos
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestObjectiveSourceConflicts(t *testing.T) {
+	MustRegisterFunction("test-conflict", NativeFunction{Func: func(x []float64) float64 { return x[0] }})
+	fit := &FitRequest{Model: "a*t", Data: map[string][]float64{"t": {1, 2}, "y": {2, 4}}}
+	external := &ExternalRequest{Program: "test-conflict"}
+	remote := &HTTPObjectiveRequest{URL: "http://localhost:1/evaluate"}
+
+	tests := []struct {
+		name    string
+		request AlgoRequest
+	}{
+		{"тестовая и зарегистрированная", AlgoRequest{Benchmark: "sphere", Native: "test-conflict"}},
+		{"Moving Peaks и зарегистрированная", AlgoRequest{Benchmark: MovingPeaksBenchmark, Native: "test-conflict"}},
+		{"многокритериальная тестовая и зарегистрированная", AlgoRequest{Benchmark: "zdt1", Native: "test-conflict"}},
+		{"тестовая и подбор модели", AlgoRequest{Benchmark: "sphere", Fit: fit}},
+		{"зарегистрированная и внешняя программа", AlgoRequest{Native: "test-conflict", External: external}},
+		{"тестовая и HTTP", AlgoRequest{Benchmark: "sphere", HTTP: remote}},
+		{"внешняя программа и HTTP", AlgoRequest{External: external, HTTP: remote}},
+		{"подбор модели и критерии", AlgoRequest{Fit: fit, Objectives: []string{"x", "y"}}},
+	}
+	for _, test := range tests {
+		test.request.Iterations = 1
+		if _, err := NewAlgo(test.request); err == nil || !strings.Contains(err.Error(), "задана одновременно") {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
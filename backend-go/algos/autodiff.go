@@ -0,0 +1,550 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/expr-lang/expr/ast"
+)
+
+// dual — число с производными по всем координатам точки (прямой режим
+// автоматического дифференцирования); d == nil означает нулевые производные
+type dual struct {
+	v float64
+	d []float64
+}
+
+// combine возвращает ca·a' + cb·b'
+func combine(a []float64, ca float64, b []float64, cb float64) []float64 {
+	if a == nil && b == nil {
+		return nil
+	}
+	n := max(len(a), len(b))
+	d := make([]float64, n)
+	for i := range a {
+		d[i] += ca * a[i]
+	}
+	for i := range b {
+		d[i] += cb * b[i]
+	}
+	return d
+}
+
+// chain — значение функции одного аргумента и её производная в точке
+func chain(a dual, value, derivative float64) dual {
+	return dual{v: value, d: combine(a.d, derivative, nil, 0)}
+}
+
+// Gradient вычисляет значение выражения и его градиент в точке
+// обходом дерева выражения без оптимизаций.
+func (e *Expression) Gradient(vars []float64) (float64, []float64, error) {
+	if len(vars) < e.Dimensions {
+		return 0, nil, fmt.Errorf("ожидался массив как минимум из %d элементов", e.Dimensions)
+	}
+
+	x := make([]dual, len(vars))
+	for i := range vars {
+		x[i] = dual{v: vars[i], d: make([]float64, len(vars))}
+		x[i].d[i] = 1
+	}
+	evaluator := &dualEvaluator{x: x, scope: map[string]any{}}
+	if e.clock != nil {
+		evaluator.t = float64(e.clock.Iteration())
+		evaluator.evals = float64(e.clock.Evaluations())
+	}
+
+	result, err := evaluator.eval(e.tree)
+	if err != nil {
+		return 0, nil, err
+	}
+	value, err := toDual(result)
+	if err != nil {
+		return 0, nil, err
+	}
+	gradient := value.d
+	if gradient == nil {
+		gradient = make([]float64, len(vars))
+	}
+	return value.v, gradient, nil
+}
+
+type dualEvaluator struct {
+	x        []dual
+	t, evals float64
+	// переменные let и указатели # и #acc внутри reduce
+	scope map[string]any
+}
+
+func toDual(value any) (dual, error) {
+	switch v := value.(type) {
+	case dual:
+		return v, nil
+	case int:
+		return dual{v: float64(v)}, nil
+	case float64:
+		return dual{v: v}, nil
+	}
+	return dual{}, fmt.Errorf("ожидалось число, получено %T", value)
+}
+
+func toInt(value any) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case dual:
+		return int(v.v), nil
+	case float64:
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("ожидалось целое число, получено %T", value)
+}
+
+// withScope вычисляет узел, временно связав имя со значением
+func (ev *dualEvaluator) withScope(name string, value any, node ast.Node) (any, error) {
+	previous, had := ev.scope[name]
+	ev.scope[name] = value
+	defer func() {
+		if had {
+			ev.scope[name] = previous
+		} else {
+			delete(ev.scope, name)
+		}
+	}()
+	return ev.eval(node)
+}
+
+func (ev *dualEvaluator) eval(node ast.Node) (any, error) {
+	switch n := node.(type) {
+	case *ast.IntegerNode:
+		return n.Value, nil
+	case *ast.FloatNode:
+		return dual{v: n.Value}, nil
+	case *ast.BoolNode:
+		return n.Value, nil
+	case *ast.ConstantNode:
+		return n.Value, nil
+
+	case *ast.IdentifierNode:
+		if value, ok := ev.scope[n.Value]; ok {
+			return value, nil
+		}
+		switch n.Value {
+		case varsName:
+			return ev.x, nil
+		case dimsName:
+			return dual{v: float64(len(ev.x))}, nil
+		case iterationName:
+			return dual{v: ev.t}, nil
+		case evaluationsName:
+			return dual{v: ev.evals}, nil
+		}
+		if value, ok := mathEnv()[n.Value].(float64); ok {
+			return dual{v: value}, nil
+		}
+		return nil, fmt.Errorf("неизвестная переменная %s", n.Value)
+
+	case *ast.PointerNode:
+		return ev.scope["#"+n.Name], nil
+
+	case *ast.MemberNode:
+		vector, err := ev.eval(n.Node)
+		if err != nil {
+			return nil, err
+		}
+		index, err := ev.evalInt(n.Property)
+		if err != nil {
+			return nil, err
+		}
+		x, ok := vector.([]dual)
+		if !ok || index < 0 || index >= len(x) {
+			return nil, fmt.Errorf("индекс %d вне массива координат", index)
+		}
+		return x[index], nil
+
+	case *ast.UnaryNode:
+		value, err := ev.eval(n.Node)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Operator {
+		case "!", "not":
+			b, ok := value.(bool)
+			if !ok {
+				return nil, errors.New("отрицание применимо только к условию")
+			}
+			return !b, nil
+		case "+":
+			return value, nil
+		case "-":
+			if i, ok := value.(int); ok {
+				return -i, nil
+			}
+			a, err := toDual(value)
+			if err != nil {
+				return nil, err
+			}
+			return chain(a, -a.v, -1), nil
+		}
+
+	case *ast.BinaryNode:
+		return ev.evalBinary(n)
+
+	case *ast.ConditionalNode:
+		cond, err := ev.evalBool(n.Cond)
+		if err != nil {
+			return nil, err
+		}
+		if cond {
+			return ev.eval(n.Exp1)
+		}
+		return ev.eval(n.Exp2)
+
+	case *ast.VariableDeclaratorNode:
+		value, err := ev.eval(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		return ev.withScope(n.Name, value, n.Expr)
+
+	case *ast.SequenceNode:
+		var result any
+		for _, item := range n.Nodes {
+			var err error
+			if result, err = ev.eval(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+
+	case *ast.CallNode:
+		callee, ok := n.Callee.(*ast.IdentifierNode)
+		if !ok {
+			break
+		}
+		if callee.Value == indexName {
+			number, err := ev.evalInt(n.Arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return vectorIndex(number, len(ev.x))
+		}
+		args := make([]dual, len(n.Arguments))
+		for i, argument := range n.Arguments {
+			value, err := ev.eval(argument)
+			if err != nil {
+				return nil, err
+			}
+			if args[i], err = toDual(value); err != nil {
+				return nil, err
+			}
+		}
+		return dualCall(callee.Value, args)
+
+	case *ast.BuiltinNode:
+		return ev.evalBuiltin(n)
+	}
+	return nil, fmt.Errorf("дифференцирование не поддерживает %s", ast.Dump(node))
+}
+
+func (ev *dualEvaluator) evalInt(node ast.Node) (int, error) {
+	value, err := ev.eval(node)
+	if err != nil {
+		return 0, err
+	}
+	return toInt(value)
+}
+
+func (ev *dualEvaluator) evalBool(node ast.Node) (bool, error) {
+	value, err := ev.eval(node)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, errors.New("ожидалось условие")
+	}
+	return b, nil
+}
+
+func (ev *dualEvaluator) evalBinary(n *ast.BinaryNode) (any, error) {
+	switch n.Operator {
+	case "&&", "and", "||", "or":
+		left, err := ev.evalBool(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		if (n.Operator == "&&" || n.Operator == "and") != left {
+			return left, nil
+		}
+		return ev.evalBool(n.Right)
+	}
+
+	left, err := ev.eval(n.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := ev.eval(n.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	// целочисленная арифметика нужна для индексов и диапазонов
+	li, lok := left.(int)
+	ri, rok := right.(int)
+	if lok && rok {
+		switch n.Operator {
+		case "..":
+			r := make([]int, 0, max(0, ri-li+1))
+			for i := li; i <= ri; i++ {
+				r = append(r, i)
+			}
+			return r, nil
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		}
+	}
+
+	if lb, ok := left.(bool); ok {
+		rb, ok := right.(bool)
+		if !ok {
+			return nil, errors.New("сравнение условия с числом")
+		}
+		switch n.Operator {
+		case "==":
+			return lb == rb, nil
+		case "!=":
+			return lb != rb, nil
+		}
+		return nil, fmt.Errorf("оператор %s не применим к условиям", n.Operator)
+	}
+
+	a, err := toDual(left)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toDual(right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Operator {
+	case "+":
+		return dual{v: a.v + b.v, d: combine(a.d, 1, b.d, 1)}, nil
+	case "-":
+		return dual{v: a.v - b.v, d: combine(a.d, 1, b.d, -1)}, nil
+	case "*":
+		return dual{v: a.v * b.v, d: combine(a.d, b.v, b.d, a.v)}, nil
+	case "/":
+		return dual{v: a.v / b.v, d: combine(a.d, 1/b.v, b.d, -a.v/(b.v*b.v))}, nil
+	case "%":
+		q := math.Floor(a.v / b.v)
+		return dual{v: a.v - q*b.v, d: combine(a.d, 1, b.d, -q)}, nil
+	case "^", "**":
+		return dualPow(a, b), nil
+	case "==":
+		return a.v == b.v, nil
+	case "!=":
+		return a.v != b.v, nil
+	case "<":
+		return a.v < b.v, nil
+	case ">":
+		return a.v > b.v, nil
+	case "<=":
+		return a.v <= b.v, nil
+	case ">=":
+		return a.v >= b.v, nil
+	}
+	return nil, fmt.Errorf("дифференцирование не поддерживает оператор %s", n.Operator)
+}
+
+func dualPow(a, b dual) dual {
+	value := math.Pow(a.v, b.v)
+	// производная по показателю есть только при переменном показателе
+	cb := 0.0
+	if b.d != nil && a.v > 0 {
+		cb = value * math.Log(a.v)
+	}
+	ca := 0.0
+	if b.v != 0 {
+		ca = b.v * math.Pow(a.v, b.v-1)
+	}
+	return dual{v: value, d: combine(a.d, ca, b.d, cb)}
+}
+
+func (ev *dualEvaluator) evalBuiltin(n *ast.BuiltinNode) (any, error) {
+	switch n.Name {
+	case "int":
+		value, err := ev.eval(n.Arguments[0])
+		if err != nil {
+			return nil, err
+		}
+		return toInt(value)
+
+	case "float":
+		value, err := ev.eval(n.Arguments[0])
+		if err != nil {
+			return nil, err
+		}
+		return toDual(value)
+
+	case "reduce":
+		// свёртки sum и prod, раскрытые в variablesPatcher
+		collection, err := ev.eval(n.Arguments[0])
+		if err != nil {
+			return nil, err
+		}
+		items, ok := collection.([]int)
+		if !ok {
+			return nil, errors.New("reduce поддерживается только по диапазону")
+		}
+		predicate, ok := n.Arguments[1].(*ast.PredicateNode)
+		if !ok {
+			return nil, errors.New("reduce ожидает предикат")
+		}
+
+		acc, err := ev.eval(n.Arguments[2])
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			ev.scope["#"] = item
+			ev.scope["#acc"] = acc
+			if acc, err = ev.eval(predicate.Node); err != nil {
+				return nil, err
+			}
+		}
+		delete(ev.scope, "#")
+		delete(ev.scope, "#acc")
+		return acc, nil
+	}
+	return nil, fmt.Errorf("дифференцирование не поддерживает функцию %s", n.Name)
+}
+
+// dualCall вычисляет функцию из mathEnv вместе с производными
+func dualCall(name string, args []dual) (dual, error) {
+	unary := func(f func(float64) float64, derivative func(float64) float64) (dual, error) {
+		if len(args) != 1 {
+			return dual{}, fmt.Errorf("%s ожидает один аргумент", name)
+		}
+		return chain(args[0], f(args[0].v), derivative(args[0].v)), nil
+	}
+	constant := func(f func(float64) float64) (dual, error) {
+		return unary(f, func(float64) float64 { return 0 })
+	}
+
+	switch name {
+	case "sin":
+		return unary(math.Sin, math.Cos)
+	case "cos":
+		return unary(math.Cos, func(v float64) float64 { return -math.Sin(v) })
+	case "tan":
+		return unary(math.Tan, func(v float64) float64 { return 1 + math.Tan(v)*math.Tan(v) })
+	case "log", "ln":
+		return unary(math.Log, func(v float64) float64 { return 1 / v })
+	case "log2":
+		return unary(math.Log2, func(v float64) float64 { return 1 / (v * math.Ln2) })
+	case "log10":
+		return unary(math.Log10, func(v float64) float64 { return 1 / (v * math.Ln10) })
+	case "sqrt":
+		return unary(math.Sqrt, func(v float64) float64 { return 0.5 / math.Sqrt(v) })
+	case "cbrt":
+		return unary(math.Cbrt, func(v float64) float64 { return 1 / (3 * math.Cbrt(v) * math.Cbrt(v)) })
+	case "exp":
+		return unary(math.Exp, math.Exp)
+	case "abs":
+		return unary(math.Abs, sign)
+	case "asin":
+		return unary(math.Asin, func(v float64) float64 { return 1 / math.Sqrt(1-v*v) })
+	case "acos":
+		return unary(math.Acos, func(v float64) float64 { return -1 / math.Sqrt(1-v*v) })
+	case "atan":
+		return unary(math.Atan, func(v float64) float64 { return 1 / (1 + v*v) })
+	case "sinh":
+		return unary(math.Sinh, math.Cosh)
+	case "cosh":
+		return unary(math.Cosh, math.Sinh)
+	case "tanh":
+		return unary(math.Tanh, func(v float64) float64 { return 1 - math.Tanh(v)*math.Tanh(v) })
+	case "erf":
+		return unary(math.Erf, func(v float64) float64 { return 2 / math.SqrtPi * math.Exp(-v*v) })
+	case "gamma":
+		return unary(math.Gamma, func(v float64) float64 { return math.Gamma(v) * digamma(v) })
+	case "floor":
+		return constant(math.Floor)
+	case "ceil":
+		return constant(math.Ceil)
+	case "round":
+		return constant(math.Round)
+	case "sign":
+		return constant(sign)
+
+	case "pow":
+		if len(args) != 2 {
+			return dual{}, errors.New("pow ожидает два аргумента")
+		}
+		return dualPow(args[0], args[1]), nil
+	case "atan2":
+		if len(args) != 2 {
+			return dual{}, errors.New("atan2 ожидает два аргумента")
+		}
+		y, x := args[0], args[1]
+		r := x.v*x.v + y.v*y.v
+		return dual{v: math.Atan2(y.v, x.v), d: combine(y.d, x.v/r, x.d, -y.v/r)}, nil
+	case "hypot":
+		if len(args) != 2 {
+			return dual{}, errors.New("hypot ожидает два аргумента")
+		}
+		a, b := args[0], args[1]
+		h := math.Hypot(a.v, b.v)
+		if h == 0 {
+			return dual{}, nil
+		}
+		return dual{v: h, d: combine(a.d, a.v/h, b.d, b.v/h)}, nil
+	case "min", "max":
+		if len(args) == 0 {
+			return dual{}, fmt.Errorf("%s ожидает хотя бы один аргумент", name)
+		}
+		best := args[0]
+		for _, arg := range args[1:] {
+			if (name == "min" && arg.v < best.v) || (name == "max" && arg.v > best.v) {
+				best = arg
+			}
+		}
+		return best, nil
+	case "clamp":
+		if len(args) != 3 {
+			return dual{}, errors.New("clamp ожидает три аргумента")
+		}
+		switch {
+		case args[0].v < args[1].v:
+			return args[1], nil
+		case args[0].v > args[2].v:
+			return args[2], nil
+		}
+		return args[0], nil
+	}
+	return dual{}, fmt.Errorf("дифференцирование не поддерживает функцию %s", name)
+}
+
+// digamma — логарифмическая производная гамма-функции
+func digamma(x float64) float64 {
+	if x <= 0 && x == math.Floor(x) {
+		return math.NaN()
+	}
+	if x < 0 {
+		// формула отражения
+		return digamma(1-x) - math.Pi/math.Tan(math.Pi*x)
+	}
+	result := 0.0
+	for x < 6 {
+		result -= 1 / x
+		x++
+	}
+	x2 := 1 / (x * x)
+	return result + math.Log(x) - 0.5/x - x2*(1.0/12-x2*(1.0/120-x2*(1.0/252)))
+}
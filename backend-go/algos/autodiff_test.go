@@ -0,0 +1,89 @@
+package algos
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGradientExact(t *testing.T) {
+	tests := []struct {
+		expression string
+		point      []float64
+		value      float64
+		gradient   []float64
+	}{
+		{"x^2 + 3*y", []float64{2, 5}, 19, []float64{4, 3}},
+		{"x * y", []float64{2, 5}, 10, []float64{5, 2}},
+		{"x / y", []float64{1, 2}, 0.5, []float64{0.5, -0.25}},
+		{"sin(x) * cos(y)", []float64{0, 0}, 0, []float64{1, 0}},
+		{"exp(x) + ln(y)", []float64{0, 2}, 1 + math.Ln2, []float64{1, 0.5}},
+		{"x ^ y", []float64{2, 3}, 8, []float64{12, 8 * math.Ln2}},
+		{"sqrt(x^2 + y^2)", []float64{3, 4}, 5, []float64{0.6, 0.8}},
+		{"hypot(x, y)", []float64{3, 4}, 5, []float64{0.6, 0.8}},
+		{"abs(x - y)", []float64{1, 3}, 2, []float64{-1, 1}},
+		{"x > y ? x : 2*y", []float64{1, 3}, 6, []float64{0, 2}},
+		{"let a = x * y; a^2", []float64{1, 2}, 4, []float64{8, 4}},
+		{"sum(i, 1, n, i * x[i]^2)", []float64{1, 1}, 3, []float64{2, 4}},
+		{"prod(i, 1, n, x[i])", []float64{2, 3}, 6, []float64{3, 2}},
+		{"max(x, y) + min(x, 0)", []float64{1, 3}, 3, []float64{0, 1}},
+		{"clamp(x, 0, 1) + floor(y)", []float64{0.5, 2.5}, 2.5, []float64{1, 0}},
+	}
+	for _, test := range tests {
+		e, err := CompileExpression(test.expression)
+		if err != nil {
+			t.Fatalf("%s: %v", test.expression, err)
+		}
+		value, gradient, err := e.Gradient(test.point)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		if math.Abs(value-test.value) > 1e-12 {
+			t.Errorf("%s: значение %v, want %v", test.expression, value, test.value)
+		}
+		for j := range gradient {
+			if math.Abs(gradient[j]-test.gradient[j]) > 1e-12 {
+				t.Errorf("%s: градиент %v, want %v", test.expression, gradient, test.gradient)
+				break
+			}
+		}
+	}
+}
+
+// производные всех гладких функций сверяются с центральными разностями
+func TestGradientFiniteDifferences(t *testing.T) {
+	expressions := []string{
+		"tan(x) + log2(y) + log10(x + y)",
+		"cbrt(x) * sinh(y) + cosh(x) - tanh(y)",
+		"asin(x / 2) + acos(y / 4) + atan(x * y)",
+		"erf(x) + gamma(y)",
+		"pow(x, 3) + atan2(y, x)",
+		"x ** y / (1 + x)",
+		"(1 - x)^2 + 100 * (y - x^2)^2",
+	}
+	point := []float64{0.7, 1.3}
+	const h = 1e-6
+	for _, expression := range expressions {
+		e, err := CompileExpression(expression)
+		if err != nil {
+			t.Fatalf("%s: %v", expression, err)
+		}
+		value, gradient, err := e.Gradient(point)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		if want := e.Eval(point); math.Abs(value-want) > 1e-12 {
+			t.Errorf("%s: значение %v, want %v", expression, value, want)
+		}
+		for j := range point {
+			plus, minus := append([]float64{}, point...), append([]float64{}, point...)
+			plus[j] += h
+			minus[j] -= h
+			want := (e.Eval(plus) - e.Eval(minus)) / (2 * h)
+			if math.Abs(gradient[j]-want) > 1e-5*math.Max(1, math.Abs(want)) {
+				t.Errorf("%s: ∂/∂x%d = %v, разностная оценка %v", expression, j+1, gradient[j], want)
+			}
+		}
+	}
+}
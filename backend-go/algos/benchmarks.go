@@ -0,0 +1,213 @@
+package algos
+
+import (
+	"math"
+)
+
+// Benchmark — тестовая функция с известным глобальным минимумом.
+type Benchmark struct {
+	Func func([]float64) float64
+	// границы поиска по умолчанию, одинаковые для всех координат
+	Bounds [2]float64
+	// фиксированная размерность; 0 — функция определена для любой размерности
+	Dimensions int
+	// глобальный минимум для размерности n; ok == false, если он неизвестен,
+	// position == nil, если известно только значение
+	Optimum func(n int) (position []float64, value float64, ok bool)
+}
+
+var Benchmarks = map[string]Benchmark{
+	"sphere": {
+		Func:    sphere,
+		Bounds:  [2]float64{-5.12, 5.12},
+		Optimum: constantOptimum(0, 0),
+	},
+	"rastrigin": {
+		Func:    rastrigin,
+		Bounds:  [2]float64{-5.12, 5.12},
+		Optimum: constantOptimum(0, 0),
+	},
+	"ackley": {
+		Func:    ackley,
+		Bounds:  [2]float64{-32.768, 32.768},
+		Optimum: constantOptimum(0, 0),
+	},
+	"rosenbrock": {
+		Func:    rosenbrock,
+		Bounds:  [2]float64{-5, 10},
+		Optimum: constantOptimum(1, 0),
+	},
+	"griewank": {
+		Func:    griewank,
+		Bounds:  [2]float64{-600, 600},
+		Optimum: constantOptimum(0, 0),
+	},
+	"schwefel": {
+		Func:    schwefel,
+		Bounds:  [2]float64{-500, 500},
+		Optimum: constantOptimum(420.9687, 0),
+	},
+	"levy": {
+		Func:    levy,
+		Bounds:  [2]float64{-10, 10},
+		Optimum: constantOptimum(1, 0),
+	},
+	"michalewicz": {
+		Func:    michalewicz,
+		Bounds:  [2]float64{0, math.Pi},
+		Optimum: michalewiczOptimum,
+	},
+	"styblinskiTang": {
+		Func:   styblinskiTang,
+		Bounds: [2]float64{-5, 5},
+		Optimum: func(n int) ([]float64, float64, bool) {
+			return filled(n, -2.903534), -39.16617 * float64(n), true
+		},
+	},
+	"himmelblau": {
+		Func:       himmelblau,
+		Bounds:     [2]float64{-5, 5},
+		Dimensions: 2,
+		Optimum:    fixedOptimum([]float64{3, 2}, 0),
+	},
+	"easom": {
+		Func:       easom,
+		Bounds:     [2]float64{-100, 100},
+		Dimensions: 2,
+		Optimum:    fixedOptimum([]float64{math.Pi, math.Pi}, -1),
+	},
+	"eggholder": {
+		Func:       eggholder,
+		Bounds:     [2]float64{-512, 512},
+		Dimensions: 2,
+		Optimum:    fixedOptimum([]float64{512, 404.2319}, -959.6407),
+	},
+}
+
+// оптимум в точке (c, c, ..., c)
+func constantOptimum(c, value float64) func(int) ([]float64, float64, bool) {
+	return func(n int) ([]float64, float64, bool) {
+		return filled(n, c), value, true
+	}
+}
+
+func fixedOptimum(position []float64, value float64) func(int) ([]float64, float64, bool) {
+	return func(int) ([]float64, float64, bool) {
+		return append([]float64(nil), position...), value, true
+	}
+}
+
+func filled(n int, c float64) []float64 {
+	position := make([]float64, n)
+	for i := range position {
+		position[i] = c
+	}
+	return position
+}
+
+func michalewiczOptimum(n int) ([]float64, float64, bool) {
+	switch n {
+	case 2:
+		return []float64{2.20, 1.57}, -1.8013, true
+	case 5:
+		return nil, -4.687658, true
+	case 10:
+		return nil, -9.66015, true
+	}
+	return nil, 0, false
+}
+
+func sphere(x []float64) float64 {
+	sum := 0.0
+	for _, xi := range x {
+		sum += xi * xi
+	}
+	return sum
+}
+
+func rastrigin(x []float64) float64 {
+	sum := 10 * float64(len(x))
+	for _, xi := range x {
+		sum += xi*xi - 10*math.Cos(2*math.Pi*xi)
+	}
+	return sum
+}
+
+func ackley(x []float64) float64 {
+	n := float64(len(x))
+	squares, cosines := 0.0, 0.0
+	for _, xi := range x {
+		squares += xi * xi
+		cosines += math.Cos(2 * math.Pi * xi)
+	}
+	return -20*math.Exp(-0.2*math.Sqrt(squares/n)) - math.Exp(cosines/n) + 20 + math.E
+}
+
+func rosenbrock(x []float64) float64 {
+	sum := 0.0
+	for i := 0; i < len(x)-1; i++ {
+		sum += 100*math.Pow(x[i+1]-x[i]*x[i], 2) + math.Pow(1-x[i], 2)
+	}
+	return sum
+}
+
+func griewank(x []float64) float64 {
+	sum, prod := 0.0, 1.0
+	for i, xi := range x {
+		sum += xi * xi / 4000
+		prod *= math.Cos(xi / math.Sqrt(float64(i+1)))
+	}
+	return 1 + sum - prod
+}
+
+// Schwefel 2.26
+func schwefel(x []float64) float64 {
+	sum := 418.9829 * float64(len(x))
+	for _, xi := range x {
+		sum -= xi * math.Sin(math.Sqrt(math.Abs(xi)))
+	}
+	return sum
+}
+
+func levy(x []float64) float64 {
+	w := make([]float64, len(x))
+	for i, xi := range x {
+		w[i] = 1 + (xi-1)/4
+	}
+
+	last := w[len(w)-1]
+	sum := math.Pow(math.Sin(math.Pi*w[0]), 2) + math.Pow(last-1, 2)*(1+math.Pow(math.Sin(2*math.Pi*last), 2))
+	for _, wi := range w[:len(w)-1] {
+		sum += math.Pow(wi-1, 2) * (1 + 10*math.Pow(math.Sin(math.Pi*wi+1), 2))
+	}
+	return sum
+}
+
+func michalewicz(x []float64) float64 {
+	const m = 10
+	sum := 0.0
+	for i, xi := range x {
+		sum -= math.Sin(xi) * math.Pow(math.Sin(float64(i+1)*xi*xi/math.Pi), 2*m)
+	}
+	return sum
+}
+
+func styblinskiTang(x []float64) float64 {
+	sum := 0.0
+	for _, xi := range x {
+		sum += math.Pow(xi, 4) - 16*xi*xi + 5*xi
+	}
+	return sum / 2
+}
+
+func himmelblau(x []float64) float64 {
+	return math.Pow(x[0]*x[0]+x[1]-11, 2) + math.Pow(x[0]+x[1]*x[1]-7, 2)
+}
+
+func easom(x []float64) float64 {
+	return -math.Cos(x[0]) * math.Cos(x[1]) * math.Exp(-(math.Pow(x[0]-math.Pi, 2) + math.Pow(x[1]-math.Pi, 2)))
+}
+
+func eggholder(x []float64) float64 {
+	return -(x[1]+47)*math.Sin(math.Sqrt(math.Abs(x[0]/2+x[1]+47))) - x[0]*math.Sin(math.Sqrt(math.Abs(x[0]-(x[1]+47))))
+}
@@ -0,0 +1,171 @@
+package algos
+
+import (
+	"math"
+)
+
+// MultiObjectiveBenchmark — многокритериальная тестовая задача с известным фронтом Парето.
+type MultiObjectiveBenchmark struct {
+	Objectives []func([]float64) float64
+	Bounds     [2]float64
+	// размерность по умолчанию
+	Dimensions int
+	// опорная точка для гиперобъёма
+	ReferencePoint []float64
+	// равномерная выборка из истинного фронта
+	ReferenceFront func(points int) [][]float64
+}
+
+var MultiObjectiveBenchmarks = map[string]MultiObjectiveBenchmark{
+	"zdt1": {
+		Objectives:     []func([]float64) float64{zdtF1, zdt1F2},
+		Bounds:         [2]float64{0, 1},
+		Dimensions:     30,
+		ReferencePoint: []float64{1.1, 1.1},
+		ReferenceFront: func(points int) [][]float64 {
+			return curveFront(points, func(f1 float64) float64 { return 1 - math.Sqrt(f1) })
+		},
+	},
+	"zdt2": {
+		Objectives:     []func([]float64) float64{zdtF1, zdt2F2},
+		Bounds:         [2]float64{0, 1},
+		Dimensions:     30,
+		ReferencePoint: []float64{1.1, 1.1},
+		ReferenceFront: func(points int) [][]float64 {
+			return curveFront(points, func(f1 float64) float64 { return 1 - f1*f1 })
+		},
+	},
+	"zdt3": {
+		Objectives:     []func([]float64) float64{zdtF1, zdt3F2},
+		Bounds:         [2]float64{0, 1},
+		Dimensions:     30,
+		ReferencePoint: []float64{1.1, 1.1},
+		ReferenceFront: func(points int) [][]float64 {
+			// фронт ZDT3 разрывный: берём недоминируемую часть кривой
+			return nonDominated(curveFront(points, func(f1 float64) float64 {
+				return 1 - math.Sqrt(f1) - f1*math.Sin(10*math.Pi*f1)
+			}))
+		},
+	},
+	"dtlz1": {
+		Objectives:     []func([]float64) float64{dtlz1F(0), dtlz1F(1), dtlz1F(2)},
+		Bounds:         [2]float64{0, 1},
+		Dimensions:     7,
+		ReferencePoint: []float64{1, 1, 1},
+		ReferenceFront: func(points int) [][]float64 {
+			// плоскость f1 + f2 + f3 = 0.5
+			front := simplexGrid(points)
+			for _, point := range front {
+				for m := range point {
+					point[m] /= 2
+				}
+			}
+			return front
+		},
+	},
+	"dtlz2": {
+		Objectives:     []func([]float64) float64{dtlz2F(0), dtlz2F(1), dtlz2F(2)},
+		Bounds:         [2]float64{0, 1},
+		Dimensions:     12,
+		ReferencePoint: []float64{1.1, 1.1, 1.1},
+		ReferenceFront: func(points int) [][]float64 {
+			// часть единичной сферы в положительном октанте
+			front := simplexGrid(points)
+			for _, point := range front {
+				norm := math.Sqrt(point[0]*point[0] + point[1]*point[1] + point[2]*point[2])
+				for m := range point {
+					point[m] /= norm
+				}
+			}
+			return front
+		},
+	},
+}
+
+func curveFront(points int, f2 func(float64) float64) [][]float64 {
+	front := make([][]float64, points)
+	for i := range front {
+		f1 := float64(i) / float64(points-1)
+		front[i] = []float64{f1, f2(f1)}
+	}
+	return front
+}
+
+// simplexGrid возвращает около points точек симплекса f1 + f2 + f3 = 1
+func simplexGrid(points int) [][]float64 {
+	divisions := max(1, int(math.Sqrt(2*float64(points))))
+	front := make([][]float64, 0, points)
+	for i := 0; i <= divisions; i++ {
+		for j := 0; j <= divisions-i; j++ {
+			a, b := float64(i)/float64(divisions), float64(j)/float64(divisions)
+			front = append(front, []float64{a, b, 1 - a - b})
+		}
+	}
+	return front
+}
+
+func zdtF1(x []float64) float64 {
+	return x[0]
+}
+
+func zdtG(x []float64) float64 {
+	sum := 0.0
+	for _, xi := range x[1:] {
+		sum += xi
+	}
+	return 1 + 9*sum/float64(len(x)-1)
+}
+
+func zdt1F2(x []float64) float64 {
+	g := zdtG(x)
+	return g * (1 - math.Sqrt(x[0]/g))
+}
+
+func zdt2F2(x []float64) float64 {
+	g := zdtG(x)
+	return g * (1 - math.Pow(x[0]/g, 2))
+}
+
+func zdt3F2(x []float64) float64 {
+	g := zdtG(x)
+	return g * (1 - math.Sqrt(x[0]/g) - x[0]/g*math.Sin(10*math.Pi*x[0]))
+}
+
+// DTLZ с тремя критериями: первые две координаты задают положение на фронте,
+// остальные — расстояние до него
+func dtlz1F(m int) func([]float64) float64 {
+	return func(x []float64) float64 {
+		k := float64(len(x) - 2)
+		g := 0.0
+		for _, xi := range x[2:] {
+			g += (xi-0.5)*(xi-0.5) - math.Cos(20*math.Pi*(xi-0.5))
+		}
+		g = 100 * (k + g)
+
+		switch m {
+		case 0:
+			return 0.5 * x[0] * x[1] * (1 + g)
+		case 1:
+			return 0.5 * x[0] * (1 - x[1]) * (1 + g)
+		}
+		return 0.5 * (1 - x[0]) * (1 + g)
+	}
+}
+
+func dtlz2F(m int) func([]float64) float64 {
+	return func(x []float64) float64 {
+		g := 0.0
+		for _, xi := range x[2:] {
+			g += (xi - 0.5) * (xi - 0.5)
+		}
+
+		a, b := x[0]*math.Pi/2, x[1]*math.Pi/2
+		switch m {
+		case 0:
+			return (1 + g) * math.Cos(a) * math.Cos(b)
+		case 1:
+			return (1 + g) * math.Cos(a) * math.Sin(b)
+		}
+		return (1 + g) * math.Sin(a)
+	}
+}
@@ -0,0 +1,121 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type BoundaryRequest struct {
+	// clamp, reflect, wrap, random, midpoint или penalty
+	Method string `json:"method,omitempty"`
+	// коэффициент штрафа за расстояние до области поиска для penalty
+	Penalty *float64 `json:"penalty,omitempty"`
+}
+
+// способы обработки выхода за границы поиска
+const (
+	ClampBoundary    = "clamp"    // на ближайшую границу
+	ReflectBoundary  = "reflect"  // зеркально от границы внутрь области
+	WrapBoundary     = "wrap"     // периодически с противоположной стороны
+	RandomBoundary   = "random"   // случайное значение в границах
+	MidpointBoundary = "midpoint" // в середину между прежним положением и нарушенной границей
+	PenaltyBoundary  = "penalty"  // точка остаётся снаружи, к значению функции добавляется штраф
+)
+
+// Boundary — общий для алгоритмов способ вернуть новую точку в область поиска.
+// Дискретные координаты всегда переносятся на ближайшую границу: вне её
+// допустимых значений нет.
+type Boundary struct {
+	Method  string
+	Penalty float64
+}
+
+func NewBoundary(request BoundaryRequest) (*Boundary, error) {
+	boundary := &Boundary{
+		Method:  request.Method,
+		Penalty: setDefault(request.Penalty, 1e6),
+	}
+	switch boundary.Method {
+	case "":
+		boundary.Method = ClampBoundary
+	case ClampBoundary, ReflectBoundary, WrapBoundary, RandomBoundary, MidpointBoundary, PenaltyBoundary:
+	default:
+		return nil, fmt.Errorf("неизвестный способ обработки границ %q", request.Method)
+	}
+	if boundary.Penalty < 0 {
+		return nil, errors.New("коэффициент штрафа за выход за границы не может быть отрицательным")
+	}
+	return boundary, nil
+}
+
+// confine возвращает новую точку агента в область поиска выбранным способом;
+// parent — положение, из которого агент сделал шаг, или nil. Точка изменяется на месте.
+func (algo *Algo) confine(position, parent []float64) []float64 {
+	for j := range position {
+		lo, hi := algo.Bounds[j][0], algo.Bounds[j][1]
+		if (position[j] < lo || position[j] > hi) && algo.continuous(j) {
+			switch algo.Boundary.Method {
+			case ReflectBoundary:
+				// многократное отражение — это отражение с периодом 2·(hi − lo)
+				width := hi - lo
+				offset := math.Mod(position[j]-lo, 2*width)
+				if offset < 0 {
+					offset += 2 * width
+				}
+				if offset > width {
+					offset = 2*width - offset
+				}
+				position[j] = lo + offset
+			case WrapBoundary:
+				offset := math.Mod(position[j]-lo, hi-lo)
+				if offset < 0 {
+					offset += hi - lo
+				}
+				position[j] = lo + offset
+			case RandomBoundary:
+				position[j] = lo + algo.Rng.Float64()*(hi-lo)
+			case MidpointBoundary:
+				if parent != nil {
+					bound := lo
+					if position[j] > hi {
+						bound = hi
+					}
+					position[j] = (parent[j] + bound) / 2
+				}
+			case PenaltyBoundary:
+				continue
+			}
+		}
+		position[j] = math.Max(lo, math.Min(position[j], hi))
+		if algo.Variables != nil {
+			position[j] = algo.Variables[j].snap(position[j], algo.Bounds[j])
+		}
+	}
+	return position
+}
+
+// outside возвращает суммарное расстояние от точки до области поиска по координатам
+func outside(position []float64, bounds [][]float64) float64 {
+	distance := 0.0
+	for j, bound := range bounds {
+		distance += math.Max(0, bound[0]-position[j]) + math.Max(0, position[j]-bound[1])
+	}
+	return distance
+}
+
+// boundaryPenalty — штраф за выход точки за границы поиска; 0, если штраф не задан
+func (algo *Algo) boundaryPenalty(position []float64) float64 {
+	if algo.Boundary.Method != PenaltyBoundary {
+		return 0
+	}
+	return algo.Boundary.Penalty * outside(position, algo.Bounds)
+}
+
+// penalize добавляет к функции штраф за выход за границы поиска
+func (algo *Algo) penalize(function func([]float64) float64) func([]float64) float64 {
+	boundary, bounds := algo.Boundary, algo.Bounds
+	return func(position []float64) float64 {
+		return function(position) + boundary.Penalty*outside(position, bounds)
+	}
+}
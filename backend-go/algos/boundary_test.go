@@ -0,0 +1,72 @@
+package algos
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConfine(t *testing.T) {
+	tests := []struct {
+		method string
+		value  float64
+		parent []float64
+		want   float64
+	}{
+		{ClampBoundary, 12, nil, 10},
+		{ClampBoundary, -3, nil, 0},
+		{ClampBoundary, 4, nil, 4},
+		{ReflectBoundary, 12, nil, 8},
+		{ReflectBoundary, -3, nil, 3},
+		{ReflectBoundary, 25, nil, 5},
+		{ReflectBoundary, -23, nil, 3},
+		{WrapBoundary, 12, nil, 2},
+		{WrapBoundary, -3, nil, 7},
+		{WrapBoundary, 25, nil, 5},
+		{MidpointBoundary, 12, []float64{6}, 8},
+		{MidpointBoundary, -4, []float64{6}, 3},
+		{MidpointBoundary, 12, nil, 10},
+		{PenaltyBoundary, 12, nil, 12},
+		{PenaltyBoundary, 4, nil, 4},
+	}
+	for _, test := range tests {
+		algo := confineAlgo(t, test.method)
+		if got := algo.confine([]float64{test.value}, test.parent); math.Abs(got[0]-test.want) > 1e-12 {
+			t.Errorf("%s: confine(%v, %v) = %v, want %v", test.method, test.value, test.parent, got[0], test.want)
+		}
+	}
+
+	algo := confineAlgo(t, RandomBoundary)
+	for _, value := range []float64{-100, 11, 1e9} {
+		if got := algo.confine([]float64{value}, nil); got[0] < 0 || got[0] > 10 {
+			t.Errorf("random: confine(%v) = %v вне границ", value, got[0])
+		}
+	}
+
+	algo = confineAlgo(t, PenaltyBoundary)
+	if penalty := algo.boundaryPenalty([]float64{12}); penalty != 2e6 {
+		t.Errorf("штраф за выход на 2 = %v, want 2e6", penalty)
+	}
+}
+
+// confineAlgo создаёт одномерную задачу с границами [0, 10] и заданным способом обработки границ
+func confineAlgo(t *testing.T, method string) *Algo {
+	size, dimensions := 5, 1
+	algo, err := NewAlgo(AlgoRequest{
+		Func: "x^2", Iterations: 1, PopulationSize: &size, NumDimensions: &dimensions,
+		Bounds: [][]float64{{0, 10}}, Boundary: &BoundaryRequest{Method: method},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { algo.Close() })
+	return algo
+}
+
+func TestBoundaryRequestErrors(t *testing.T) {
+	negative := -1.0
+	for _, request := range []BoundaryRequest{{Method: "bounce"}, {Method: PenaltyBoundary, Penalty: &negative}} {
+		if _, err := NewBoundary(request); err == nil {
+			t.Errorf("%+v: ожидалась ошибка", request)
+		}
+	}
+}
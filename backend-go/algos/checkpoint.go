@@ -0,0 +1,358 @@
+package algos
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// SnapshotVersion — версия формата снимка; снимки других версий не восстанавливаются
+const SnapshotVersion = 1
+
+// Snapshot — полное состояние алгоритма между итерациями. Алгоритм,
+// созданный по тому же запросу и восстановленный из снимка через Resume,
+// продолжает работу точно так же, как продолжил бы без остановки.
+//
+// Снимок хранится в двоичном виде (MarshalBinary): значения функции
+// бывают бесконечными, а в JSON их не записать.
+type Snapshot struct {
+	Version   int
+	Algorithm string
+
+	// номер следующей итерации и показания часов
+	Next        int
+	Iteration   int
+	Evaluations int64
+
+	Population   [][]float64
+	Fitness      []float64
+	BestPosition []float64
+	BestValue    float64
+	// номер агента, стоящего в лучшем решении; -1, если такого агента нет
+	BestAgent int
+
+	Rng GeneratorState
+	// последний ответ до снимка
+	State Response
+	// история лучших значений и сработавший критерий остановки
+	History    []float64
+	StopReason string
+
+	Archive     *ParetoFront
+	Noise       *NoiseState
+	MovingPeaks *MovingPeaksState
+	Constraints *ConstraintsState
+	Transform   *Transform
+
+	// состояние конкретного алгоритма
+	GWO  *GWOState
+	ABC  *ABCState
+	AFSA *AFSAState
+	// значения критериев агентов многокритериальных MOFA и MOABC
+	Values [][]float64
+}
+
+// snapshotData кодируется gob без методов Snapshot
+type snapshotData Snapshot
+
+func (s *Snapshot) MarshalBinary() ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode((*snapshotData)(s)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	var decoded snapshotData
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return fmt.Errorf("повреждённый снимок: %w", err)
+	}
+	if decoded.Version != SnapshotVersion {
+		return fmt.Errorf("версия снимка %d не поддерживается", decoded.Version)
+	}
+	*s = Snapshot(decoded)
+	return nil
+}
+
+// GeneratorState — состояние вихря Мерсенна: вектор состояния и позиция в нём
+type GeneratorState struct {
+	State []uint64
+	Index int
+}
+
+// параметры 64-битного вихря Мерсенна MT19937-64
+const (
+	mtSize    = 312
+	mtShift   = 156
+	mtMatrix  = 0xB5026F5AA96619E9
+	mtHighBit = 0xFFFFFFFF80000000
+	mtLowBits = 0x000000007FFFFFFF
+)
+
+// generator — 64-битный вихрь Мерсенна, выдающий ту же последовательность,
+// что и github.com/seehuhn/mt19937. Своя реализация нужна потому, что
+// состояние mt19937 не экспортируется, а снимок должен сохранять его целиком:
+// восстановление не зависит от того, сколько чисел уже выдано.
+type generator struct {
+	vector [mtSize]uint64
+	index  int
+}
+
+func newGenerator(seed int64) *generator {
+	g := &generator{}
+	g.Seed(seed)
+	return g
+}
+
+func (g *generator) Seed(seed int64) {
+	x := &g.vector
+	x[0] = uint64(seed)
+	for i := uint64(1); i < mtSize; i++ {
+		x[i] = 6364136223846793005*(x[i-1]^(x[i-1]>>62)) + i
+	}
+	g.index = mtSize
+}
+
+func (g *generator) Uint64() uint64 {
+	x := &g.vector
+	if g.index >= mtSize {
+		for i := range mtSize {
+			y := x[i]&mtHighBit | x[(i+1)%mtSize]&mtLowBits
+			x[i] = x[(i+mtShift)%mtSize] ^ y>>1 ^ (y&1)*mtMatrix
+		}
+		g.index = 0
+	}
+	y := x[g.index]
+	y ^= (y >> 29) & 0x5555555555555555
+	y ^= (y << 17) & 0x71D67FFFEDA60000
+	y ^= (y << 37) & 0xFFF7EEE000000000
+	y ^= y >> 43
+	g.index++
+	return y
+}
+
+func (g *generator) Int63() int64 {
+	return int64(g.Uint64() & (1<<63 - 1))
+}
+
+func (g *generator) state() GeneratorState {
+	return GeneratorState{State: slices.Clone(g.vector[:]), Index: g.index}
+}
+
+func (g *generator) restore(state GeneratorState) {
+	copy(g.vector[:], state.State)
+	g.index = state.Index
+}
+
+func (state GeneratorState) valid() bool {
+	return len(state.State) == mtSize && state.Index >= 0 && state.Index <= mtSize
+}
+
+type NoiseState struct {
+	Rng        GeneratorState
+	Elite      []float64
+	EliteSum   float64
+	EliteCount int
+}
+
+type MovingPeaksState struct {
+	Rng        GeneratorState
+	NextChange int
+	Positions  [][]float64
+	Heights    []float64
+	Widths     []float64
+	Shifts     [][]float64
+}
+
+type ConstraintsState struct {
+	Iteration     int
+	Epsilon       float64
+	WorstFeasible float64
+	// сохранённые значения в точках по их ключам, см. Constraints.evaluated
+	Evaluated map[string]CachedValues
+	Previous  map[string]CachedValues
+}
+
+// checkpoint сохраняет общее для всех алгоритмов состояние
+func (algo *Algo) checkpoint(name string) *Snapshot {
+	snapshot := &Snapshot{
+		Version:     SnapshotVersion,
+		Algorithm:   name,
+		Next:        algo.next,
+		Iteration:   algo.Clock.Iteration(),
+		Evaluations: int64(algo.Clock.Evaluations()),
+		Population:  cloneMatrix(algo.Population),
+		Fitness:     slices.Clone(algo.Fitness),
+		BestValue:   algo.GlobalBestValue,
+		BestAgent:   algo.bestAgent,
+		Rng:         algo.source.state(),
+		State:       algo.state,
+		History:     slices.Clone(algo.History),
+		StopReason:  algo.stopped,
+		Transform:   algo.Transform,
+	}
+	if algo.GlobalBestPosition != nil {
+		snapshot.BestPosition = slices.Clone(algo.GlobalBestPosition)
+	}
+
+	if algo.Archive != nil {
+		snapshot.Archive = &ParetoFront{
+			Positions: cloneMatrix(algo.Archive.Positions),
+			Values:    cloneMatrix(algo.Archive.Values),
+		}
+	}
+	if noise := algo.Noise; noise != nil {
+		noise.mu.Lock()
+		snapshot.Noise = &NoiseState{
+			Rng:        noise.source.state(),
+			Elite:      slices.Clone(noise.elite),
+			EliteSum:   noise.eliteSum,
+			EliteCount: noise.eliteCount,
+		}
+		noise.mu.Unlock()
+	}
+	if mp := algo.MovingPeaks; mp != nil {
+		mp.mu.Lock()
+		snapshot.MovingPeaks = &MovingPeaksState{
+			Rng:        mp.source.state(),
+			NextChange: mp.nextChange,
+			Positions:  cloneMatrix(mp.positions),
+			Heights:    slices.Clone(mp.heights),
+			Widths:     slices.Clone(mp.widths),
+			Shifts:     cloneMatrix(mp.shifts),
+		}
+		mp.mu.Unlock()
+	}
+	if c := algo.Constraints; c != nil {
+		c.mu.Lock()
+		snapshot.Constraints = &ConstraintsState{
+			Iteration:     c.iteration,
+			Epsilon:       c.Epsilon,
+			WorstFeasible: c.worstFeasible,
+		}
+		c.mu.Unlock()
+		c.cacheMu.Lock()
+		snapshot.Constraints.Evaluated = maps.Clone(c.evaluated)
+		snapshot.Constraints.Previous = maps.Clone(c.previous)
+		c.cacheMu.Unlock()
+	}
+	return snapshot
+}
+
+// restore проверяет, что снимок сделан тем же алгоритмом для той же задачи,
+// и восстанавливает общее состояние
+func (algo *Algo) restore(snapshot *Snapshot, name string) error {
+	if snapshot.Version != SnapshotVersion {
+		return fmt.Errorf("версия снимка %d не поддерживается", snapshot.Version)
+	}
+	if snapshot.Algorithm != name {
+		return fmt.Errorf("снимок сделан алгоритмом %s, а не %s", snapshot.Algorithm, name)
+	}
+	if len(snapshot.Population) != algo.PopulationSize || len(snapshot.Fitness) != algo.PopulationSize {
+		return errors.New("несоответствие размера популяции в снимке и в запросе")
+	}
+	for _, position := range snapshot.Population {
+		if len(position) != algo.NumDimensions {
+			return errors.New("несоответствие размерности снимка и размерности задачи")
+		}
+	}
+	if snapshot.BestAgent >= algo.PopulationSize {
+		return errors.New("повреждённый снимок: неверный номер лучшего агента")
+	}
+	if (snapshot.Archive != nil) != (algo.Archive != nil) ||
+		(snapshot.Noise != nil) != (algo.Noise != nil) ||
+		(snapshot.MovingPeaks != nil) != (algo.MovingPeaks != nil) ||
+		(snapshot.Constraints != nil) != (algo.Constraints != nil) ||
+		(snapshot.Transform != nil) != (algo.Transform != nil) {
+		return errors.New("снимок сделан для другой задачи")
+	}
+	if mp := snapshot.MovingPeaks; mp != nil && len(mp.Positions) != len(algo.MovingPeaks.positions) {
+		return errors.New("несоответствие числа пиков в снимке и в запросе")
+	}
+	if !snapshot.Rng.valid() ||
+		snapshot.Noise != nil && !snapshot.Noise.Rng.valid() ||
+		snapshot.MovingPeaks != nil && !snapshot.MovingPeaks.Rng.valid() {
+		return errors.New("повреждённый снимок: неверное состояние генератора случайных чисел")
+	}
+
+	algo.next = snapshot.Next
+	algo.Clock.iteration.Store(int64(snapshot.Iteration))
+	algo.Clock.evaluations.Store(snapshot.Evaluations)
+	algo.Population = cloneMatrix(snapshot.Population)
+	algo.Fitness = slices.Clone(snapshot.Fitness)
+	algo.GlobalBestValue = snapshot.BestValue
+	algo.GlobalBestPosition = slices.Clone(snapshot.BestPosition)
+	algo.bestAgent = snapshot.BestAgent
+	if snapshot.BestAgent >= 0 {
+		// положения агентов не изменяются на месте, поэтому общий срез и копия равноценны
+		algo.GlobalBestPosition = algo.Population[snapshot.BestAgent]
+	}
+	algo.source.restore(snapshot.Rng)
+	algo.state = snapshot.State
+	algo.History = slices.Clone(snapshot.History)
+	algo.stopped = snapshot.StopReason
+	algo.changeDetected = snapshot.State.ChangeDetected
+
+	if snapshot.Archive != nil {
+		algo.Archive.Positions = cloneMatrix(snapshot.Archive.Positions)
+		algo.Archive.Values = cloneMatrix(snapshot.Archive.Values)
+	}
+	if state := snapshot.Noise; state != nil {
+		noise := algo.Noise
+		noise.source.restore(state.Rng)
+		noise.elite = slices.Clone(state.Elite)
+		noise.eliteSum, noise.eliteCount = state.EliteSum, state.EliteCount
+	}
+	if state := snapshot.MovingPeaks; state != nil {
+		mp := algo.MovingPeaks
+		mp.source.restore(state.Rng)
+		mp.nextChange = state.NextChange
+		mp.positions = cloneMatrix(state.Positions)
+		mp.heights = slices.Clone(state.Heights)
+		mp.widths = slices.Clone(state.Widths)
+		mp.shifts = cloneMatrix(state.Shifts)
+	}
+	if state := snapshot.Constraints; state != nil {
+		c := algo.Constraints
+		c.iteration = state.Iteration
+		c.Epsilon = state.Epsilon
+		c.worstFeasible = state.WorstFeasible
+		if c.cached {
+			c.evaluated = maps.Clone(state.Evaluated)
+			c.previous = maps.Clone(state.Previous)
+			if c.evaluated == nil {
+				c.evaluated = map[string]CachedValues{}
+			}
+		}
+	}
+	if snapshot.Transform != nil {
+		// целевые функции ссылаются на это преобразование, поэтому оно меняется на месте:
+		// случайный сдвиг и поворот при запросе без seed иначе получились бы другими
+		*algo.Transform = *snapshot.Transform
+	}
+	return nil
+}
+
+// resume связывает восстановленный алгоритм с контекстом вместо begin;
+// ограничение времени отсчитывается заново
+func (algo *Algo) resume(ctx context.Context) Response {
+	algo.finish()
+	algo.cancel = algo.start(ctx)
+	return algo.state
+}
+
+func cloneMatrix(matrix [][]float64) [][]float64 {
+	if matrix == nil {
+		return nil
+	}
+	clone := make([][]float64, len(matrix))
+	for i, row := range matrix {
+		clone[i] = slices.Clone(row)
+	}
+	return clone
+}
@@ -0,0 +1,115 @@
+package algos
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/seehuhn/mt19937"
+)
+
+// собственный генератор выдаёт ту же последовательность, что и mt19937,
+// и после восстановления состояния продолжает её с того же места
+func TestGeneratorMatchesMT19937(t *testing.T) {
+	for _, seed := range []int64{0, 1, 42, -7} {
+		reference := mt19937.New()
+		reference.Seed(seed)
+		g := newGenerator(seed)
+		for i := range 1000 {
+			if i == 500 {
+				// восстановление в новый генератор посреди вектора состояния
+				state := g.state()
+				g = newGenerator(0)
+				g.restore(state)
+			}
+			if got, want := g.Uint64(), reference.Uint64(); got != want {
+				t.Fatalf("seed %d, число %d: %x, want %x", seed, i, got, want)
+			}
+			if got, want := g.Int63(), reference.Int63(); got != want {
+				t.Fatalf("seed %d, число %d: Int63 = %x, want %x", seed, i, got, want)
+			}
+		}
+	}
+}
+
+// алгоритм, восстановленный из снимка, выдаёт те же ответы, что и без остановки
+func TestCheckpointResume(t *testing.T) {
+	request := func() AlgoRequest {
+		size, seed, dimensions := 12, 7, 3
+		return AlgoRequest{
+			Func: "(1-x)^2 + 100*(y-x^2)^2 + sin(5*z)", Iterations: 15, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+			Noise:       &NoiseRequest{Level: 0.1, Resampling: "elite"},
+			Constraints: &ConstraintsRequest{Inequalities: []string{"x + y - 1"}, Method: EpsilonLevel},
+		}
+	}
+	multi := func() AlgoRequest {
+		size, seed, dimensions := 10, 3, 2
+		return AlgoRequest{Objectives: []string{"x^2 + y^2", "(x-2)^2 + y^2"}, Iterations: 10, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions}
+	}
+	subpopulations, iterations := 3, 3
+	algorithms := map[string]func() (Algorithm, error){
+		"GWO": func() (Algorithm, error) { return NewGWO(GWORequest{AlgoRequest: request()}) },
+		"ABC": func() (Algorithm, error) { return NewABC(ABCRequest{AlgoRequest: request()}) },
+		"AFSA": func() (Algorithm, error) {
+			return NewAFSA(AFSARequest{AlgoRequest: request(), Visual: []float64{0.1, 0.5}})
+		},
+		"FA": func() (Algorithm, error) { return NewFA(FARequest{AlgoRequest: request()}) },
+		"SFLA": func() (Algorithm, error) {
+			return NewSFLA(SFLARequest{AlgoRequest: request(), SubpopulationsCount: &subpopulations, IMax: &iterations})
+		},
+		"MOGWO": func() (Algorithm, error) { return NewMOGWO(GWORequest{AlgoRequest: multi()}) },
+		"MOABC": func() (Algorithm, error) { return NewMOABC(ABCRequest{AlgoRequest: multi()}) },
+		"MOFA":  func() (Algorithm, error) { return NewMOFA(FARequest{AlgoRequest: multi()}) },
+	}
+
+	// run возвращает ответы всех итераций; split >= 0 — номер шага, перед которым
+	// алгоритм сохраняется в снимок и восстанавливается в новый экземпляр
+	run := func(create func() (Algorithm, error), split int) []string {
+		algorithm, err := create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var responses []string
+		record := func(response Response) {
+			data, _ := json.Marshal(response)
+			responses = append(responses, string(data))
+		}
+		record(algorithm.Init(context.Background()))
+		for i := 0; ; i++ {
+			if i == split {
+				data, err := algorithm.Checkpoint().MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				algorithm.Close()
+				var snapshot Snapshot
+				if err := snapshot.UnmarshalBinary(data); err != nil {
+					t.Fatal(err)
+				}
+				if algorithm, err = create(); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := algorithm.Resume(context.Background(), &snapshot); err != nil {
+					t.Fatal(err)
+				}
+			}
+			response, ok := algorithm.Step()
+			if !ok {
+				break
+			}
+			record(response)
+		}
+		algorithm.Close()
+		return responses
+	}
+
+	for name, create := range algorithms {
+		full := run(create, -1)
+		for _, split := range []int{0, 5} {
+			if resumed := run(create, split); !slices.Equal(resumed, full) {
+				t.Errorf("%s: ответы после восстановления на шаге %d отличаются", name, split)
+			}
+		}
+	}
+}
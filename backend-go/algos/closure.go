@@ -0,0 +1,593 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/expr-lang/expr/ast"
+)
+
+// Выражение переводится в дерево замыканий над float64 и int: виртуальная
+// машина expr упаковывает каждое промежуточное число в interface и читает
+// координаты через reflect, замыкания обходятся без выделений памяти.
+// Узлы, которые сюда не переводятся, вычисляет виртуальная машина.
+
+// frame — координаты, время и значения let и reduce одного вызова
+type frame struct {
+	x        []float64
+	t, evals float64
+	floats   []float64
+	ints     []int
+	bools    []bool
+	err      error
+}
+
+// fail запоминает первую ошибку вычисления; результат вызова при ошибке не используется
+func (fr *frame) fail(err error) {
+	if fr.err == nil {
+		fr.err = err
+	}
+}
+
+type valueKind int
+
+const (
+	floatKind valueKind = iota
+	intKind
+	boolKind
+	// диапазон from..to — только как первый аргумент reduce
+	rangeKind
+)
+
+// closure — скомпилированный узел; заполнено поле, соответствующее kind
+type closure struct {
+	kind     valueKind
+	f        func(*frame) float64
+	i        func(*frame) int
+	b        func(*frame) bool
+	from, to func(*frame) int
+}
+
+func floatClosure(f func(*frame) float64) closure { return closure{kind: floatKind, f: f} }
+func intClosure(i func(*frame) int) closure       { return closure{kind: intKind, i: i} }
+func boolClosure(b func(*frame) bool) closure     { return closure{kind: boolKind, b: b} }
+
+// asFloat приводит число к float64, как это делает expr в смешанной арифметике
+func (c closure) asFloat() (func(*frame) float64, bool) {
+	switch c.kind {
+	case floatKind:
+		return c.f, true
+	case intKind:
+		i := c.i
+		return func(fr *frame) float64 { return float64(i(fr)) }, true
+	}
+	return nil, false
+}
+
+// after возвращает узел, перед вычислением которого выполняется before
+func (c closure) after(before func(*frame)) closure {
+	switch c.kind {
+	case floatKind:
+		f := c.f
+		c.f = func(fr *frame) float64 { before(fr); return f(fr) }
+	case intKind:
+		i := c.i
+		c.i = func(fr *frame) int { before(fr); return i(fr) }
+	case boolKind:
+		b := c.b
+		c.b = func(fr *frame) bool { before(fr); return b(fr) }
+	}
+	return c
+}
+
+// run вычисляет узел ради ошибок, отбрасывая значение
+func (c closure) run() func(*frame) {
+	switch c.kind {
+	case floatKind:
+		return func(fr *frame) { c.f(fr) }
+	case intKind:
+		return func(fr *frame) { c.i(fr) }
+	case boolKind:
+		return func(fr *frame) { c.b(fr) }
+	}
+	return func(*frame) {}
+}
+
+var errUnsupported = errors.New("узел не переводится в замыкания")
+
+type binding struct {
+	name string
+	kind valueKind
+	slot int
+}
+
+type closureCompiler struct {
+	// имена let и указатели # и #acc внутри reduce, видимые в текущем узле
+	scope []binding
+	// число ячеек для значений каждого типа
+	floats, ints, bools int
+}
+
+// compileClosure переводит дерево выражения без оптимизаций в замыкание
+func compileClosure(tree ast.Node) (*closureCompiler, func(*frame) float64, error) {
+	c := &closureCompiler{}
+	root, err := c.compile(tree)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, ok := root.asFloat()
+	if !ok {
+		return nil, nil, errUnsupported
+	}
+	return c, f, nil
+}
+
+func (c *closureCompiler) newFrame() *frame {
+	return &frame{
+		floats: make([]float64, c.floats),
+		ints:   make([]int, c.ints),
+		bools:  make([]bool, c.bools),
+	}
+}
+
+func (c *closureCompiler) lookup(name string) (binding, bool) {
+	for i := len(c.scope) - 1; i >= 0; i-- {
+		if c.scope[i].name == name {
+			return c.scope[i], true
+		}
+	}
+	return binding{}, false
+}
+
+// bind выделяет ячейку под значение kind и делает имя видимым до unbind
+func (c *closureCompiler) bind(name string, kind valueKind) binding {
+	b := binding{name: name, kind: kind}
+	switch kind {
+	case floatKind:
+		b.slot, c.floats = c.floats, c.floats+1
+	case intKind:
+		b.slot, c.ints = c.ints, c.ints+1
+	case boolKind:
+		b.slot, c.bools = c.bools, c.bools+1
+	}
+	c.scope = append(c.scope, b)
+	return b
+}
+
+func (c *closureCompiler) unbind() {
+	c.scope = c.scope[:len(c.scope)-1]
+}
+
+func load(b binding) closure {
+	slot := b.slot
+	switch b.kind {
+	case floatKind:
+		return floatClosure(func(fr *frame) float64 { return fr.floats[slot] })
+	case intKind:
+		return intClosure(func(fr *frame) int { return fr.ints[slot] })
+	}
+	return boolClosure(func(fr *frame) bool { return fr.bools[slot] })
+}
+
+// store возвращает запись значения value в ячейку b
+func store(b binding, value closure) func(*frame) {
+	slot := b.slot
+	switch b.kind {
+	case floatKind:
+		f := value.f
+		return func(fr *frame) { fr.floats[slot] = f(fr) }
+	case intKind:
+		i := value.i
+		return func(fr *frame) { fr.ints[slot] = i(fr) }
+	}
+	v := value.b
+	return func(fr *frame) { fr.bools[slot] = v(fr) }
+}
+
+func (c *closureCompiler) compile(node ast.Node) (closure, error) {
+	switch n := node.(type) {
+	case *ast.IntegerNode:
+		v := n.Value
+		return intClosure(func(*frame) int { return v }), nil
+	case *ast.FloatNode:
+		v := n.Value
+		return floatClosure(func(*frame) float64 { return v }), nil
+	case *ast.BoolNode:
+		v := n.Value
+		return boolClosure(func(*frame) bool { return v }), nil
+
+	case *ast.IdentifierNode:
+		if b, ok := c.lookup(n.Value); ok {
+			return load(b), nil
+		}
+		switch n.Value {
+		case dimsName:
+			return floatClosure(func(fr *frame) float64 { return float64(len(fr.x)) }), nil
+		case iterationName:
+			return floatClosure(func(fr *frame) float64 { return fr.t }), nil
+		case evaluationsName:
+			return floatClosure(func(fr *frame) float64 { return fr.evals }), nil
+		}
+		if v, ok := mathEnv()[n.Value].(float64); ok {
+			return floatClosure(func(*frame) float64 { return v }), nil
+		}
+
+	case *ast.PointerNode:
+		if b, ok := c.lookup("#" + n.Name); ok {
+			return load(b), nil
+		}
+
+	case *ast.MemberNode:
+		vector, ok := n.Node.(*ast.IdentifierNode)
+		if !ok || vector.Value != varsName {
+			break
+		}
+		index, err := c.compile(n.Property)
+		if err != nil || index.kind != intKind {
+			break
+		}
+		i := index.i
+		return floatClosure(func(fr *frame) float64 {
+			k := i(fr)
+			// отрицательный индекс expr отсчитывает от конца массива
+			if k < 0 {
+				k += len(fr.x)
+			}
+			if k < 0 || k >= len(fr.x) {
+				fr.fail(fmt.Errorf("индекс %d вне массива координат", k))
+				return 0
+			}
+			return fr.x[k]
+		}), nil
+
+	case *ast.UnaryNode:
+		operand, err := c.compile(n.Node)
+		if err != nil {
+			return closure{}, err
+		}
+		switch {
+		case n.Operator == "+" && operand.kind != boolKind:
+			return operand, nil
+		case n.Operator == "-" && operand.kind == intKind:
+			i := operand.i
+			return intClosure(func(fr *frame) int { return -i(fr) }), nil
+		case n.Operator == "-" && operand.kind == floatKind:
+			f := operand.f
+			return floatClosure(func(fr *frame) float64 { return -f(fr) }), nil
+		case (n.Operator == "!" || n.Operator == "not") && operand.kind == boolKind:
+			b := operand.b
+			return boolClosure(func(fr *frame) bool { return !b(fr) }), nil
+		}
+
+	case *ast.BinaryNode:
+		return c.compileBinary(n)
+
+	case *ast.ConditionalNode:
+		cond, err := c.compile(n.Cond)
+		if err != nil {
+			return closure{}, err
+		}
+		yes, err := c.compile(n.Exp1)
+		if err != nil {
+			return closure{}, err
+		}
+		no, err := c.compile(n.Exp2)
+		if err != nil {
+			return closure{}, err
+		}
+		if cond.kind != boolKind {
+			break
+		}
+		b := cond.b
+		switch {
+		case yes.kind == boolKind && no.kind == boolKind:
+			return boolClosure(func(fr *frame) bool {
+				if b(fr) {
+					return yes.b(fr)
+				}
+				return no.b(fr)
+			}), nil
+		case yes.kind == intKind && no.kind == intKind:
+			return intClosure(func(fr *frame) int {
+				if b(fr) {
+					return yes.i(fr)
+				}
+				return no.i(fr)
+			}), nil
+		}
+		// ветви разных числовых типов: результат всё равно приводится к float64
+		yf, ok1 := yes.asFloat()
+		nf, ok2 := no.asFloat()
+		if !ok1 || !ok2 {
+			break
+		}
+		return floatClosure(func(fr *frame) float64 {
+			if b(fr) {
+				return yf(fr)
+			}
+			return nf(fr)
+		}), nil
+
+	case *ast.VariableDeclaratorNode:
+		value, err := c.compile(n.Value)
+		if err != nil {
+			return closure{}, err
+		}
+		if value.kind == rangeKind {
+			break
+		}
+		b := c.bind(n.Name, value.kind)
+		body, err := c.compile(n.Expr)
+		c.unbind()
+		if err != nil {
+			return closure{}, err
+		}
+		return body.after(store(b, value)), nil
+
+	case *ast.SequenceNode:
+		if len(n.Nodes) == 0 {
+			break
+		}
+		var before []func(*frame)
+		for _, item := range n.Nodes[:len(n.Nodes)-1] {
+			compiled, err := c.compile(item)
+			if err != nil {
+				return closure{}, err
+			}
+			before = append(before, compiled.run())
+		}
+		last, err := c.compile(n.Nodes[len(n.Nodes)-1])
+		if err != nil {
+			return closure{}, err
+		}
+		return last.after(func(fr *frame) {
+			for _, run := range before {
+				run(fr)
+			}
+		}), nil
+
+	case *ast.CallNode:
+		return c.compileCall(n)
+
+	case *ast.BuiltinNode:
+		return c.compileBuiltin(n)
+	}
+	return closure{}, errUnsupported
+}
+
+func (c *closureCompiler) compileBinary(n *ast.BinaryNode) (closure, error) {
+	left, err := c.compile(n.Left)
+	if err != nil {
+		return closure{}, err
+	}
+	right, err := c.compile(n.Right)
+	if err != nil {
+		return closure{}, err
+	}
+
+	if left.kind == boolKind || right.kind == boolKind {
+		if left.kind != boolKind || right.kind != boolKind {
+			return closure{}, errUnsupported
+		}
+		l, r := left.b, right.b
+		switch n.Operator {
+		case "&&", "and":
+			return boolClosure(func(fr *frame) bool { return l(fr) && r(fr) }), nil
+		case "||", "or":
+			return boolClosure(func(fr *frame) bool { return l(fr) || r(fr) }), nil
+		case "==":
+			return boolClosure(func(fr *frame) bool { return l(fr) == r(fr) }), nil
+		case "!=":
+			return boolClosure(func(fr *frame) bool { return l(fr) != r(fr) }), nil
+		}
+		return closure{}, errUnsupported
+	}
+
+	// целочисленная арифметика нужна для индексов и диапазонов
+	if left.kind == intKind && right.kind == intKind {
+		l, r := left.i, right.i
+		switch n.Operator {
+		case "..":
+			return closure{kind: rangeKind, from: l, to: r}, nil
+		case "+":
+			return intClosure(func(fr *frame) int { return l(fr) + r(fr) }), nil
+		case "-":
+			return intClosure(func(fr *frame) int { return l(fr) - r(fr) }), nil
+		case "*":
+			return intClosure(func(fr *frame) int { return l(fr) * r(fr) }), nil
+		case "%":
+			return intClosure(func(fr *frame) int {
+				a, b := l(fr), r(fr)
+				if b == 0 {
+					fr.fail(errors.New("целочисленное деление на ноль"))
+					return 0
+				}
+				return a % b
+			}), nil
+		case "==":
+			return boolClosure(func(fr *frame) bool { return l(fr) == r(fr) }), nil
+		case "!=":
+			return boolClosure(func(fr *frame) bool { return l(fr) != r(fr) }), nil
+		case "<":
+			return boolClosure(func(fr *frame) bool { return l(fr) < r(fr) }), nil
+		case ">":
+			return boolClosure(func(fr *frame) bool { return l(fr) > r(fr) }), nil
+		case "<=":
+			return boolClosure(func(fr *frame) bool { return l(fr) <= r(fr) }), nil
+		case ">=":
+			return boolClosure(func(fr *frame) bool { return l(fr) >= r(fr) }), nil
+		}
+	}
+
+	l, ok1 := left.asFloat()
+	r, ok2 := right.asFloat()
+	if !ok1 || !ok2 {
+		return closure{}, errUnsupported
+	}
+	switch n.Operator {
+	case "+":
+		return floatClosure(func(fr *frame) float64 { return l(fr) + r(fr) }), nil
+	case "-":
+		return floatClosure(func(fr *frame) float64 { return l(fr) - r(fr) }), nil
+	case "*":
+		return floatClosure(func(fr *frame) float64 { return l(fr) * r(fr) }), nil
+	case "/":
+		return floatClosure(func(fr *frame) float64 { return l(fr) / r(fr) }), nil
+	case "^", "**":
+		return floatClosure(func(fr *frame) float64 { return math.Pow(l(fr), r(fr)) }), nil
+	case "==":
+		return boolClosure(func(fr *frame) bool { return l(fr) == r(fr) }), nil
+	case "!=":
+		return boolClosure(func(fr *frame) bool { return l(fr) != r(fr) }), nil
+	case "<":
+		return boolClosure(func(fr *frame) bool { return l(fr) < r(fr) }), nil
+	case ">":
+		return boolClosure(func(fr *frame) bool { return l(fr) > r(fr) }), nil
+	case "<=":
+		return boolClosure(func(fr *frame) bool { return l(fr) <= r(fr) }), nil
+	case ">=":
+		return boolClosure(func(fr *frame) bool { return l(fr) >= r(fr) }), nil
+	}
+	return closure{}, errUnsupported
+}
+
+func (c *closureCompiler) compileCall(n *ast.CallNode) (closure, error) {
+	callee, ok := n.Callee.(*ast.IdentifierNode)
+	if !ok {
+		return closure{}, errUnsupported
+	}
+	if _, shadowed := c.lookup(callee.Value); shadowed {
+		return closure{}, errUnsupported
+	}
+
+	if callee.Value == indexName {
+		number, err := c.compile(n.Arguments[0])
+		if err != nil || number.kind != intKind {
+			return closure{}, errUnsupported
+		}
+		i := number.i
+		return intClosure(func(fr *frame) int {
+			k, err := vectorIndex(i(fr), len(fr.x))
+			if err != nil {
+				fr.fail(err)
+			}
+			return k
+		}), nil
+	}
+
+	args := make([]func(*frame) float64, len(n.Arguments))
+	for k, argument := range n.Arguments {
+		compiled, err := c.compile(argument)
+		if err != nil {
+			return closure{}, err
+		}
+		if args[k], ok = compiled.asFloat(); !ok {
+			return closure{}, errUnsupported
+		}
+	}
+
+	switch function := mathEnv()[callee.Value].(type) {
+	case func(float64) float64:
+		if len(args) == 1 {
+			a := args[0]
+			return floatClosure(func(fr *frame) float64 { return function(a(fr)) }), nil
+		}
+	case func(float64, float64) float64:
+		if len(args) == 2 {
+			a, b := args[0], args[1]
+			return floatClosure(func(fr *frame) float64 { return function(a(fr), b(fr)) }), nil
+		}
+	case func(float64, float64, float64) float64:
+		if len(args) == 3 {
+			a, b, d := args[0], args[1], args[2]
+			return floatClosure(func(fr *frame) float64 { return function(a(fr), b(fr), d(fr)) }), nil
+		}
+	case func(float64, ...float64) float64:
+		if len(args) >= 1 {
+			first, rest := args[0], args[1:]
+			return floatClosure(func(fr *frame) float64 {
+				values := make([]float64, len(rest))
+				for k, arg := range rest {
+					values[k] = arg(fr)
+				}
+				return function(first(fr), values...)
+			}), nil
+		}
+	}
+	return closure{}, errUnsupported
+}
+
+func (c *closureCompiler) compileBuiltin(n *ast.BuiltinNode) (closure, error) {
+	switch n.Name {
+	case "int", "float":
+		if len(n.Arguments) != 1 {
+			break
+		}
+		value, err := c.compile(n.Arguments[0])
+		if err != nil {
+			return closure{}, err
+		}
+		if n.Name == "float" {
+			f, ok := value.asFloat()
+			if !ok {
+				break
+			}
+			return floatClosure(f), nil
+		}
+		switch value.kind {
+		case intKind:
+			return value, nil
+		case floatKind:
+			f := value.f
+			return intClosure(func(fr *frame) int { return int(f(fr)) }), nil
+		}
+
+	case "len":
+		if vector, ok := n.Arguments[0].(*ast.IdentifierNode); ok && vector.Value == varsName {
+			return intClosure(func(fr *frame) int { return len(fr.x) }), nil
+		}
+
+	case "reduce":
+		// свёртки sum и prod, раскрытые в variablesPatcher
+		if len(n.Arguments) != 3 {
+			break
+		}
+		items, err := c.compile(n.Arguments[0])
+		if err != nil {
+			return closure{}, err
+		}
+		predicate, ok := n.Arguments[1].(*ast.PredicateNode)
+		if items.kind != rangeKind || !ok {
+			break
+		}
+		initial, err := c.compile(n.Arguments[2])
+		if err != nil || initial.kind != floatKind {
+			break
+		}
+
+		item := c.bind("#", intKind)
+		acc := c.bind("#acc", floatKind)
+		body, err := c.compile(predicate.Node)
+		c.unbind()
+		c.unbind()
+		if err != nil {
+			return closure{}, err
+		}
+		f, ok := body.asFloat()
+		if !ok {
+			break
+		}
+		from, to, init := items.from, items.to, initial.f
+		return floatClosure(func(fr *frame) float64 {
+			result := init(fr)
+			for k, last := from(fr), to(fr); k <= last; k++ {
+				fr.ints[item.slot] = k
+				fr.floats[acc.slot] = result
+				result = f(fr)
+			}
+			return result
+		}), nil
+	}
+	return closure{}, errUnsupported
+}
@@ -0,0 +1,251 @@
+package algos
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+type ConstraintsRequest struct {
+	Inequalities []string `json:"inequalities,omitempty"` // g(x) <= 0
+	Equalities   []string `json:"equalities,omitempty"`   // h(x) = 0
+	Tolerance    *float64 `json:"tolerance,omitempty"`
+	Method       string   `json:"method,omitempty"`
+	Penalty      *float64 `json:"penalty,omitempty"`
+	Epsilon      *float64 `json:"epsilon,omitempty"`
+}
+
+// способы учёта ограничений
+const (
+	StaticPenalty   = "static"      // f + R*v
+	DynamicPenalty  = "dynamic"     // f + (C*t)^2 * v^2
+	FeasibilityRule = "feasibility" // правила Деба
+	EpsilonLevel    = "epsilon"     // ε-ограничения с убывающим ε
+)
+
+// Constraints превращает целевую функцию задачи с ограничениями
+// в функцию приспособленности, которую минимизируют алгоритмы.
+type Constraints struct {
+	Inequalities []func([]float64) float64
+	Equalities   []func([]float64) float64
+	Tolerance    float64
+	Method       string
+	Penalty      float64
+	Epsilon      float64
+
+	iteration  int
+	iterations int
+	// ограничения зависят от t или evals
+	timeDependent bool
+
+	// худшее допустимое значение целевой функции для правил Деба
+	mu            sync.Mutex
+	worstFeasible float64
+
+	// значения целевой функции и нарушения в вычисленных точках: если от итерации
+	// зависит только приспособленность, популяция не вычисляется заново.
+	// Точки, к которым не обращались с прошлого обновления популяции, хранятся в previous
+	// и забываются при следующем.
+	cached              bool
+	cacheMu             sync.Mutex
+	evaluated, previous map[string]CachedValues
+}
+
+// CachedValues — значение целевой функции и нарушение ограничений в точке
+type CachedValues struct {
+	Value     float64
+	Violation float64
+}
+
+func NewConstraints(request ConstraintsRequest, iterations int, clock *Clock, helpers Helpers) (*Constraints, int, error) {
+	constraints := &Constraints{
+		Tolerance:     setDefault(request.Tolerance, 1e-4),
+		Method:        request.Method,
+		iterations:    iterations,
+		worstFeasible: math.Inf(-1),
+	}
+
+	switch request.Method {
+	case "", StaticPenalty:
+		constraints.Method = StaticPenalty
+		constraints.Penalty = setDefault(request.Penalty, 1e6)
+	case DynamicPenalty:
+		constraints.Penalty = setDefault(request.Penalty, 0.5)
+	case FeasibilityRule:
+	case EpsilonLevel:
+		constraints.Epsilon = setDefault(request.Epsilon, math.NaN())
+	default:
+		return nil, 0, fmt.Errorf("неизвестный способ учёта ограничений %q", request.Method)
+	}
+
+	if constraints.Tolerance < 0 {
+		return nil, 0, errors.New("допуск для ограничений-равенств должен быть неотрицательным")
+	}
+
+	dimensions := 0
+	compile := func(expressions []string) ([]func([]float64) float64, error) {
+		functions := make([]func([]float64) float64, len(expressions))
+		for i, expression := range expressions {
+			e, err := CompileExpressionWithHelpers(expression, helpers)
+			if err != nil {
+				return nil, errors.New("Ошибка компиляции ограничения:" + err.Error())
+			}
+			e.clock = clock
+			constraints.timeDependent = constraints.timeDependent || e.TimeDependent
+			functions[i] = e.Eval
+			dimensions = max(dimensions, e.Dimensions)
+		}
+		return functions, nil
+	}
+
+	var err error
+	if constraints.Inequalities, err = compile(request.Inequalities); err != nil {
+		return nil, 0, err
+	}
+	if constraints.Equalities, err = compile(request.Equalities); err != nil {
+		return nil, 0, err
+	}
+
+	return constraints, dimensions, nil
+}
+
+// Violation возвращает суммарное нарушение ограничений в точке
+func (c *Constraints) Violation(position []float64) float64 {
+	violation := 0.0
+	for _, g := range c.Inequalities {
+		violation += math.Max(0, g(position))
+	}
+	for _, h := range c.Equalities {
+		violation += math.Max(0, math.Abs(h(position))-c.Tolerance)
+	}
+	return violation
+}
+
+// Fitness объединяет значение целевой функции и нарушение ограничений в одно число
+func (c *Constraints) Fitness(value, violation float64) float64 {
+	switch c.Method {
+	case StaticPenalty:
+		return value + c.Penalty*violation
+
+	case DynamicPenalty:
+		k := c.Penalty * float64(c.iteration+1)
+		return value + k*k*violation*violation
+	}
+
+	// правила Деба: допустимые решения сравниваются по значению функции,
+	// недопустимые — по нарушению и всегда хуже любого допустимого;
+	// в ε-ограничениях допустимыми считаются решения с нарушением не больше ε
+	threshold := 0.0
+	if c.Method == EpsilonLevel {
+		threshold = c.epsilon()
+	}
+	if violation <= threshold {
+		c.mu.Lock()
+		c.worstFeasible = math.Max(c.worstFeasible, value)
+		c.mu.Unlock()
+		return value
+	}
+	return c.worstFeasibleValue() + violation
+}
+
+func (c *Constraints) worstFeasibleValue() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if math.IsInf(c.worstFeasible, -1) {
+		return 0
+	}
+	return c.worstFeasible
+}
+
+// уровень ε убывает до нуля к 80% итераций (Takahama, Sakai)
+func (c *Constraints) epsilon() float64 {
+	cutoff := 0.8 * float64(c.iterations)
+	if float64(c.iteration) >= cutoff {
+		return 0
+	}
+	return c.Epsilon * math.Pow(1-float64(c.iteration)/cutoff, 5)
+}
+
+// changing проверяет, что приспособленность одной и той же точки меняется от итерации
+// к итерации: кроме статического штрафа она зависит от номера итерации, а правила Деба —
+// от худшего допустимого значения
+func (c *Constraints) changing() bool {
+	return c.timeDependent || c.Method != StaticPenalty
+}
+
+// начальный уровень ε по умолчанию — среднее нарушение начальной популяции
+func (c *Constraints) initEpsilon(population [][]float64) {
+	if c.Method != EpsilonLevel || !math.IsNaN(c.Epsilon) {
+		return
+	}
+	c.Epsilon = 0
+	for _, position := range population {
+		c.Epsilon += c.Violation(position)
+	}
+	c.Epsilon /= float64(len(population))
+}
+
+func (c *Constraints) wrap(objective func([]float64) float64) func([]float64) float64 {
+	return func(position []float64) float64 {
+		return c.evaluate(position, objective(position))
+	}
+}
+
+// evaluate вычисляет нарушение в точке и приспособленность по значению функции value
+func (c *Constraints) evaluate(position []float64, value float64) float64 {
+	violation := c.Violation(position)
+	if c.cached {
+		c.cacheMu.Lock()
+		c.evaluated[positionKey(position)] = CachedValues{value, violation}
+		c.cacheMu.Unlock()
+	}
+	return c.Fitness(value, violation)
+}
+
+// lookup возвращает сохранённые значения функции и нарушения в точке
+func (c *Constraints) lookup(position []float64) (value, violation float64, ok bool) {
+	if !c.cached {
+		return 0, 0, false
+	}
+	key := positionKey(position)
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	e, ok := c.evaluated[key]
+	if !ok {
+		if e, ok = c.previous[key]; ok {
+			c.evaluated[key] = e
+		}
+	}
+	return e.Value, e.Violation, ok
+}
+
+// age забывает точки, к которым не обращались с прошлого вызова
+func (c *Constraints) age() {
+	if !c.cached {
+		return
+	}
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.previous, c.evaluated = c.evaluated, map[string]CachedValues{}
+}
+
+// forget забывает все сохранённые значения
+func (c *Constraints) forget() {
+	if !c.cached {
+		return
+	}
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.previous, c.evaluated = map[string]CachedValues{}, map[string]CachedValues{}
+}
+
+// positionKey — ключ точки: двоичная запись её координат
+func positionKey(position []float64) string {
+	key := make([]byte, 0, 8*len(position))
+	for _, x := range position {
+		key = binary.LittleEndian.AppendUint64(key, math.Float64bits(x))
+	}
+	return string(key)
+}
@@ -0,0 +1,78 @@
+package algos
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestConstraintsFitness(t *testing.T) {
+	epsilon := 1.0
+	tests := []struct {
+		name             string
+		request          ConstraintsRequest
+		iteration        int
+		value, violation float64
+		want             float64
+	}{
+		{"статический штраф", ConstraintsRequest{}, 0, 1, 0.5, 1 + 1e6*0.5},
+		{"статический штраф, допустимое решение", ConstraintsRequest{}, 5, 1, 0, 1},
+		{"динамический штраф растёт с итерацией", ConstraintsRequest{Method: DynamicPenalty}, 1, 1, 0.5, 1 + 1*0.25},
+		{"правила Деба, допустимое решение", ConstraintsRequest{Method: FeasibilityRule}, 0, 3, 0, 3},
+		{"правила Деба без допустимых решений", ConstraintsRequest{Method: FeasibilityRule}, 0, 3, 0.5, 0.5},
+		{"ε-ограничения в начале", ConstraintsRequest{Method: EpsilonLevel, Epsilon: &epsilon}, 0, 3, 0.5, 3},
+		{"ε-ограничения после 80% итераций", ConstraintsRequest{Method: EpsilonLevel, Epsilon: &epsilon}, 8, 3, 0.5, 0.5},
+	}
+	for _, test := range tests {
+		constraints, _, err := NewConstraints(test.request, 10, &Clock{}, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		constraints.iteration = test.iteration
+		if got := constraints.Fitness(test.value, test.violation); got != test.want {
+			t.Errorf("%s: Fitness = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// при смене итерации приспособленность пересчитывается по сохранённым значениям:
+// результат тот же, что при новом вычислении популяции, а вычислений не больше,
+// чем со статическим штрафом
+func TestConstraintsCache(t *testing.T) {
+	run := func(method string, cached bool) *GWO {
+		size, seed, dimensions := 20, 4, 2
+		gwo, err := newGWO(GWORequest{AlgoRequest: AlgoRequest{
+			Func: "x^2 + y^2", Iterations: 10, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+			Constraints: &ConstraintsRequest{Inequalities: []string{"1 - x - y"}, Method: method},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gwo.Constraints.cached != (method != StaticPenalty) {
+			t.Fatalf("%s: сохранение значений включено: %v", method, gwo.Constraints.cached)
+		}
+		gwo.Constraints.cached = gwo.Constraints.cached && cached
+		gwo.Init(context.Background())
+		for {
+			if _, ok := gwo.Step(); !ok {
+				break
+			}
+		}
+		gwo.Close()
+		return gwo
+	}
+
+	static := run(StaticPenalty, true).Clock.Evaluations()
+	for _, method := range []string{DynamicPenalty, FeasibilityRule, EpsilonLevel} {
+		cached, full := run(method, true), run(method, false)
+		if cached.GlobalBestValue != full.GlobalBestValue || !slices.Equal(cached.Fitness, full.Fitness) {
+			t.Errorf("%s: сохранённые значения изменили результат: %v, want %v", method, cached.GlobalBestValue, full.GlobalBestValue)
+		}
+		if evaluations := cached.Clock.Evaluations(); evaluations != static {
+			t.Errorf("%s: %d вычислений, со статическим штрафом %d", method, evaluations, static)
+		}
+		if full.Clock.Evaluations() <= static {
+			t.Errorf("%s: без сохранения значений популяция не вычислялась заново", method)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package algos
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Clock — общее для алгоритма время: номер итерации и число вычислений
+// целевой функции. Доступно в выражениях как t и evals.
+type Clock struct {
+	iteration   atomic.Int64
+	evaluations atomic.Int64
+	// наибольшее число вычислений; 0 — без ограничения
+	budget int64
+	// после отмены контекста целевая функция больше не вычисляется
+	ctx context.Context
+}
+
+func (c *Clock) Iteration() int {
+	return int(c.iteration.Load())
+}
+
+func (c *Clock) Evaluations() int {
+	return int(c.evaluations.Load())
+}
+
+// Exhausted проверяет, что бюджет вычислений израсходован или работа алгоритма отменена
+func (c *Clock) Exhausted() bool {
+	return (c.budget > 0 && c.evaluations.Load() >= c.budget) || c.cancelled()
+}
+
+func (c *Clock) cancelled() bool {
+	return c.ctx != nil && c.ctx.Err() != nil
+}
+
+// runContext возвращает контекст работы алгоритма; до запуска — context.Background()
+func (c *Clock) runContext() context.Context {
+	if c == nil || c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// reserve засчитывает до n вычислений в пределах бюджета и возвращает, сколько из них разрешено
+func (c *Clock) reserve(n int) int {
+	if c.cancelled() {
+		return 0
+	}
+	for {
+		used := c.evaluations.Load()
+		granted := int64(n)
+		if c.budget > 0 {
+			granted = max(0, min(granted, c.budget-used))
+		}
+		if c.evaluations.CompareAndSwap(used, used+granted) {
+			return int(granted)
+		}
+	}
+}
+
+// counting считает вычисления функции; сверх бюджета функция не вычисляется
+// и возвращается +Inf, поэтому такие точки алгоритмы отбрасывают
+func (c *Clock) counting(function func([]float64) float64) func([]float64) float64 {
+	return func(position []float64) float64 {
+		if c.reserve(1) == 0 {
+			return math.Inf(1)
+		}
+		return function(position)
+	}
+}
+
+type ChangeDetectionRequest struct {
+	// доля популяции, заново разбрасываемая по области поиска после изменения
+	Diversify *float64 `json:"diversify,omitempty"`
+	// минимальное изменение значения лучшего решения, считающееся изменением функции
+	Tolerance *float64 `json:"tolerance,omitempty"`
+}
+
+type ChangeDetection struct {
+	Diversify float64
+	Tolerance float64
+}
+
+func NewChangeDetection(request ChangeDetectionRequest) (*ChangeDetection, error) {
+	detection := &ChangeDetection{
+		Diversify: setDefault(request.Diversify, 0),
+		Tolerance: setDefault(request.Tolerance, 1e-12),
+	}
+	if detection.Diversify < 0 || detection.Diversify > 1 {
+		return nil, errors.New("доля переинициализируемой популяции должна быть от 0 до 1")
+	}
+	return detection, nil
+}
+
+// detectChange перевычисляет лучшее решение и, если значение изменилось,
+// заново разбрасывает часть популяции
+func (algo *Algo) detectChange() bool {
+	if algo.ChangeDetection == nil || algo.GlobalBestPosition == nil || algo.Clock.Exhausted() {
+		return false
+	}
+
+	value := algo.Func(algo.GlobalBestPosition)
+	if math.Abs(value-algo.GlobalBestValue) <= algo.ChangeDetection.Tolerance {
+		return false
+	}
+	algo.GlobalBestValue = value
+
+	count := int(math.Round(algo.ChangeDetection.Diversify * float64(algo.PopulationSize)))
+	for _, i := range algo.Rng.Perm(algo.PopulationSize)[:count] {
+		// агент, на котором достигается лучшее решение, остаётся на месте
+		if i == algo.bestAgent {
+			continue
+		}
+		algo.Population[i] = algo.randomPosition()
+	}
+	return true
+}
+
+type MovingPeaksRequest struct {
+	Peaks           *int      `json:"peaks,omitempty"`
+	ChangeFrequency *int      `json:"changeFrequency,omitempty"`
+	HeightSeverity  *float64  `json:"heightSeverity,omitempty"`
+	WidthSeverity   *float64  `json:"widthSeverity,omitempty"`
+	ShiftLength     *float64  `json:"shiftLength,omitempty"`
+	Lambda          *float64  `json:"lambda,omitempty"`
+	Heights         []float64 `json:"heights,omitempty"`
+	Widths          []float64 `json:"widths,omitempty"`
+}
+
+const MovingPeaksBenchmark = "movingPeaks"
+
+// MovingPeaks — генератор Moving Peaks (Branke, 1999): максимум из конусов,
+// высоты, ширины и положения которых меняются каждые ChangeFrequency вычислений.
+// Алгоритмы минимизируют функцию со знаком минус.
+type MovingPeaks struct {
+	ChangeFrequency int
+	HeightSeverity  float64
+	WidthSeverity   float64
+	ShiftLength     float64
+	Lambda          float64
+	Heights         [2]float64
+	Widths          [2]float64
+
+	mu         sync.Mutex
+	rng        *rand.Rand
+	source     *generator
+	clock      *Clock
+	bounds     [][]float64
+	nextChange int
+
+	positions [][]float64
+	heights   []float64
+	widths    []float64
+	shifts    [][]float64
+}
+
+var movingPeaksBounds = [2]float64{0, 100}
+
+const movingPeaksDimensions = 5
+
+func NewMovingPeaks(request MovingPeaksRequest, bounds [][]float64, clock *Clock, seed int64) (*MovingPeaks, error) {
+	mp := &MovingPeaks{
+		ChangeFrequency: setDefault(request.ChangeFrequency, 5000),
+		HeightSeverity:  setDefault(request.HeightSeverity, 7.0),
+		WidthSeverity:   setDefault(request.WidthSeverity, 1.0),
+		ShiftLength:     setDefault(request.ShiftLength, 1.0),
+		Lambda:          setDefault(request.Lambda, 0.0),
+		Heights:         [2]float64{30, 70},
+		Widths:          [2]float64{1, 12},
+		clock:           clock,
+		bounds:          bounds,
+	}
+	if request.Heights != nil {
+		if len(request.Heights) != 2 {
+			return nil, errors.New("неверно задан диапазон высот пиков")
+		}
+		mp.Heights = [2]float64(request.Heights)
+	}
+	if request.Widths != nil {
+		if len(request.Widths) != 2 {
+			return nil, errors.New("неверно задан диапазон ширин пиков")
+		}
+		mp.Widths = [2]float64(request.Widths)
+	}
+
+	peaks := setDefault(request.Peaks, 10)
+	if peaks < 1 {
+		return nil, errors.New("число пиков должно быть больше 0")
+	}
+	if mp.ChangeFrequency < 1 {
+		return nil, errors.New("период изменения функции должен быть больше 0")
+	}
+
+	mp.source = newGenerator(seed)
+	mp.rng = rand.New(mp.source)
+	mp.nextChange = mp.ChangeFrequency
+
+	mp.positions = make([][]float64, peaks)
+	mp.heights = make([]float64, peaks)
+	mp.widths = make([]float64, peaks)
+	mp.shifts = make([][]float64, peaks)
+	for i := range peaks {
+		mp.positions[i] = make([]float64, len(bounds))
+		for j, bound := range bounds {
+			mp.positions[i][j] = bound[0] + mp.rng.Float64()*(bound[1]-bound[0])
+		}
+		mp.heights[i] = mp.Heights[0] + mp.rng.Float64()*(mp.Heights[1]-mp.Heights[0])
+		mp.widths[i] = mp.Widths[0] + mp.rng.Float64()*(mp.Widths[1]-mp.Widths[0])
+		mp.shifts[i] = make([]float64, len(bounds))
+	}
+
+	return mp, nil
+}
+
+func (mp *MovingPeaks) Func(position []float64) float64 {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	for mp.clock.Evaluations() >= mp.nextChange {
+		mp.change()
+		mp.nextChange += mp.ChangeFrequency
+	}
+
+	value := math.Inf(-1)
+	for i, peak := range mp.positions {
+		distance := 0.0
+		for j := range peak {
+			distance += (position[j] - peak[j]) * (position[j] - peak[j])
+		}
+		value = math.Max(value, mp.heights[i]-mp.widths[i]*math.Sqrt(distance))
+	}
+	return -value
+}
+
+// Optimum возвращает текущее положение и значение глобального оптимума
+func (mp *MovingPeaks) Optimum() ([]float64, float64) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	best := 0
+	for i, height := range mp.heights {
+		if height > mp.heights[best] {
+			best = i
+		}
+	}
+	return slices.Clone(mp.positions[best]), -mp.heights[best]
+}
+
+func (mp *MovingPeaks) change() {
+	for i := range mp.positions {
+		mp.heights[i] = clampTo(mp.heights[i]+mp.HeightSeverity*mp.rng.NormFloat64(), mp.Heights)
+		mp.widths[i] = clampTo(mp.widths[i]+mp.WidthSeverity*mp.rng.NormFloat64(), mp.Widths)
+
+		// сдвиг — смесь случайного направления и предыдущего сдвига с весом λ
+		random := make([]float64, len(mp.bounds))
+		for j := range random {
+			random[j] = mp.rng.Float64() - 0.5
+		}
+		scaleTo(random, mp.ShiftLength)
+		for j := range random {
+			random[j] = (1-mp.Lambda)*random[j] + mp.Lambda*mp.shifts[i][j]
+		}
+		scaleTo(random, mp.ShiftLength)
+
+		for j, bound := range mp.bounds {
+			next := mp.positions[i][j] + random[j]
+			// от границ пик отражается
+			if next < bound[0] || next > bound[1] {
+				random[j] = -random[j]
+				next = mp.positions[i][j] + random[j]
+			}
+			mp.positions[i][j] = next
+		}
+		mp.shifts[i] = random
+	}
+}
+
+func clampTo(value float64, bounds [2]float64) float64 {
+	return math.Max(bounds[0], math.Min(value, bounds[1]))
+}
+
+func scaleTo(vector []float64, length float64) {
+	norm := 0.0
+	for _, v := range vector {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	if norm == 0 {
+		return
+	}
+	for j := range vector {
+		vector[j] *= length / norm
+	}
+}
@@ -0,0 +1,82 @@
+package algos
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+)
+
+// после изменения функции агент, стоящий в лучшем решении, не разбрасывается,
+// даже если алгоритм хранит копию лучшего положения
+func TestChangeKeepsBestAgent(t *testing.T) {
+	size, seed, dimensions, diversify := 10, 1, 2, 1.0
+	abc, err := newABC(ABCRequest{AlgoRequest: AlgoRequest{
+		Func: "x^2 + y^2", Iterations: 10, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+		ChangeDetection: &ChangeDetectionRequest{Diversify: &diversify},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer abc.Close()
+	abc.Init(context.Background())
+	// ABC хранит копию положения лучшего агента
+	copied := func() bool {
+		return abc.bestAgent >= 0 && &abc.Population[abc.bestAgent][0] != &abc.GlobalBestPosition[0]
+	}
+	for range 8 {
+		if copied() {
+			break
+		}
+		abc.Step()
+	}
+	if !copied() {
+		t.Fatal("лучшее решение не обновилось")
+	}
+	agent, best := abc.bestAgent, slices.Clone(abc.GlobalBestPosition)
+
+	// имитация изменения функции: сохранённое значение лучшего решения не совпадает с новым
+	abc.GlobalBestValue++
+	abc.setIteration(abc.Clock.Iteration() + 1)
+	if !abc.changeDetected {
+		t.Fatal("изменение не обнаружено")
+	}
+	if !slices.Equal(abc.Population[agent], best) {
+		t.Errorf("агент %d в лучшем решении разброшен: %v, want %v", agent, abc.Population[agent], best)
+	}
+}
+
+// значения бета- и дельта-волков пересчитываются вместе с популяцией;
+// функция только растёт со временем, поэтому устаревшие значения вожаков не вытесняются
+func TestGWOLeadersFollowChange(t *testing.T) {
+	size, seed, dimensions := 10, 1, 2
+	gwo, err := newGWO(GWORequest{AlgoRequest: AlgoRequest{
+		Func: "(x - t/2)^2 + y^2 + 10*t", Iterations: 10, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gwo.Close()
+	gwo.Init(context.Background())
+	f := func(position []float64) float64 {
+		shift := float64(gwo.Clock.Iteration()) / 2
+		return (position[0]-shift)*(position[0]-shift) + position[1]*position[1] + 20*shift
+	}
+	for {
+		if _, ok := gwo.Step(); !ok {
+			break
+		}
+		for name, leader := range map[string]struct {
+			position []float64
+			value    float64
+		}{
+			"альфа":  {gwo.GlobalBestPosition, gwo.GlobalBestValue},
+			"бета":   {gwo.beta, gwo.betaValue},
+			"дельта": {gwo.delta, gwo.deltaValue},
+		} {
+			if want := f(leader.position); math.Abs(leader.value-want) > 1e-12 {
+				t.Errorf("итерация %d: значение вожака %s %v, want %v", gwo.Clock.Iteration(), name, leader.value, want)
+			}
+		}
+	}
+}
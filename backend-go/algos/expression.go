@@ -0,0 +1,394 @@
+package algos
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+)
+
+// имена, под которыми координаты точки, размерность и время передаются в выражение
+const (
+	varsName        = "_x"
+	dimsName        = "n"
+	iterationName   = "t"
+	evaluationsName = "evals"
+	// проверка индекса x[i], вычисляемого во время работы
+	indexName = "_index"
+)
+
+var indexedVarRegexp = regexp.MustCompile(`^x(\d+)$`)
+
+// псевдонимы первых координат
+var aliasIndices = map[string]int{"x": 0, "y": 1, "z": 2}
+
+// Expression — скомпилированная целевая функция от произвольного числа переменных.
+//
+// Координаты доступны как x, y, z (первые три), x1..xn и x[i] (нумерация с 1),
+// размерность — как n, номер итерации и число вычислений — как t и evals. Для свёрток по индексу поддерживаются
+// sum(i, from, to, body) и prod(i, from, to, body). Кусочные функции записываются
+// через cond ? a : b или if cond { a } else { b }, подвыражения — через let.
+//
+// Eval можно вызывать из нескольких горутин одновременно: каждый вызов берёт
+// из пула собственную рабочую область. Выражение вычисляется деревом замыканий
+// (closure.go), а если какой-то узел в замыкания не переводится — виртуальной машиной expr.
+type Expression struct {
+	program *vm.Program
+	states  sync.Pool
+	clock   *Clock
+
+	// замыкание, вычисляющее выражение, и пул его рабочих областей; nil — только виртуальная машина
+	fast   func(*frame) float64
+	frames sync.Pool
+
+	// дерево без оптимизаций: по нему строятся замыкания и вычисляется градиент
+	tree ast.Node
+
+	// минимальная размерность, при которой выражение определено
+	Dimensions int
+	// выражение зависит от t или evals
+	TimeDependent bool
+}
+
+type evalState struct {
+	vm  vm.VM
+	env map[string]any
+}
+
+// mathEnv — функции и константы, доступные в выражениях
+func mathEnv() map[string]any {
+	return map[string]any{
+		"sin":  math.Sin,
+		"cos":  math.Cos,
+		"tan":  math.Tan,
+		"PI":   math.Pi,
+		"log":  math.Log,
+		"ln":   math.Log,
+		"sqrt": math.Sqrt,
+		"abs":  math.Abs,
+		"pow":  math.Pow,
+		"exp":  math.Exp,
+
+		"E":     math.E,
+		"asin":  math.Asin,
+		"acos":  math.Acos,
+		"atan":  math.Atan,
+		"atan2": math.Atan2,
+		"sinh":  math.Sinh,
+		"cosh":  math.Cosh,
+		"tanh":  math.Tanh,
+		"log2":  math.Log2,
+		"log10": math.Log10,
+		"cbrt":  math.Cbrt,
+		"hypot": math.Hypot,
+		"erf":   math.Erf,
+		"gamma": math.Gamma,
+		"floor": math.Floor,
+		"ceil":  math.Ceil,
+		"round": math.Round,
+		"min":   minOf,
+		"max":   maxOf,
+		"clamp": clamp,
+		"sign":  sign,
+	}
+}
+
+func minOf(first float64, rest ...float64) float64 {
+	for _, value := range rest {
+		first = math.Min(first, value)
+	}
+	return first
+}
+
+func maxOf(first float64, rest ...float64) float64 {
+	for _, value := range rest {
+		first = math.Max(first, value)
+	}
+	return first
+}
+
+func clamp(value, low, high float64) float64 {
+	return math.Max(low, math.Min(value, high))
+}
+
+func sign(value float64) float64 {
+	switch {
+	case value > 0:
+		return 1
+	case value < 0:
+		return -1
+	}
+	return 0
+}
+
+func newExpressionEnv() map[string]any {
+	env := mathEnv()
+	env[varsName] = []float64{}
+	env[dimsName] = 0.0
+
+	env[iterationName] = 0.0
+	env[evaluationsName] = 0.0
+
+	// заглушки, чтобы парсер не принял sum и prod за встроенные функции expr;
+	// сами вызовы заменяются на reduce в variablesPatcher
+	env["sum"] = func(...any) float64 { return 0 }
+	env["prod"] = func(...any) float64 { return 0 }
+	return env
+}
+
+// checkedIndex переводит номер координаты x[i] в индекс массива _x: без проверки
+// x[0] прочитал бы последнюю координату через отрицательный индекс expr
+var checkedIndex = expr.Function(indexName, func(params ...any) (any, error) {
+	return vectorIndex(params[0].(int), params[1].(int))
+}, new(func(int, int) int))
+
+func vectorIndex(number, length int) (int, error) {
+	if number < 1 || number > length {
+		return 0, fmt.Errorf("индекс x[%d] вне диапазона 1..%d", number, length)
+	}
+	return number - 1, nil
+}
+
+func CompileExpression(expression string) (*Expression, error) {
+	return CompileExpressionWithHelpers(expression, nil)
+}
+
+// CompileExpressionWithHelpers компилирует выражение, подставляя в него
+// вспомогательные функции из запроса
+func CompileExpressionWithHelpers(expression string, helpers Helpers) (*Expression, error) {
+	helpersPatcher := &helpersPatcher{helpers: helpers}
+	patcher := &variablesPatcher{scalars: make(map[ast.Node]bool)}
+	// дерево без оптимизаций нужно замыканиям и градиенту; оптимизатор expr
+	// лишь сворачивает константы, так что виртуальная машина почти не теряет в скорости
+	program, err := expr.Compile(expression, expr.Env(newExpressionEnv()), checkedIndex, expr.Optimize(false),
+		expr.Patch(helpersPatcher), expr.Patch(patcher), expr.AsFloat64())
+	if helpersPatcher.err != nil {
+		return nil, helpersPatcher.err
+	}
+	if patcher.err != nil {
+		return nil, patcher.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Expression{
+		program:       program,
+		tree:          program.Node(),
+		Dimensions:    patcher.dimensions,
+		TimeDependent: patcher.timeDependent,
+	}
+	e.states.New = func() any {
+		return &evalState{env: newExpressionEnv()}
+	}
+	if compiler, fast, err := compileClosure(e.tree); err == nil {
+		e.fast = fast
+		e.frames.New = func() any {
+			return compiler.newFrame()
+		}
+	}
+	return e, nil
+}
+
+func (e *Expression) Eval(vars []float64) float64 {
+	if len(vars) < e.Dimensions {
+		fmt.Printf("Ошибка: ожидался массив как минимум из %d элементов\n", e.Dimensions)
+		return math.NaN()
+	}
+
+	var output float64
+	if e.fast != nil {
+		fr := e.frames.Get().(*frame)
+		fr.x, fr.err = vars, nil
+		if e.clock != nil {
+			fr.t = float64(e.clock.Iteration())
+			fr.evals = float64(e.clock.Evaluations())
+		}
+		output = e.fast(fr)
+		err := fr.err
+		fr.x = nil
+		e.frames.Put(fr)
+		if err != nil {
+			fmt.Println("Ошибка вычисления:", err)
+			return math.Inf(1)
+		}
+	} else {
+		var err error
+		if output, err = e.run(vars); err != nil {
+			fmt.Println("Ошибка вычисления:", err)
+			return math.Inf(1)
+		}
+	}
+
+	// ошибка вычисления, как и неопределённое значение, — худшее значение функции
+	if math.IsNaN(output) {
+		return math.Inf(1)
+	}
+	return output
+}
+
+// run вычисляет выражение виртуальной машиной expr
+func (e *Expression) run(vars []float64) (float64, error) {
+	state := e.states.Get().(*evalState)
+	defer e.states.Put(state)
+
+	state.env[varsName] = vars
+	state.env[dimsName] = float64(len(vars))
+	if e.clock != nil {
+		state.env[iterationName] = float64(e.clock.Iteration())
+		state.env[evaluationsName] = float64(e.clock.Evaluations())
+	}
+
+	output, err := state.vm.Run(e.program, state.env)
+	if err != nil {
+		return 0, err
+	}
+	return output.(float64), nil
+}
+
+func ConvertMathExpressionToFunc(expression string) (func([]float64) float64, error) {
+	e, err := CompileExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	return e.Eval, nil
+}
+
+// variablesPatcher переписывает обращения к координатам в обращения к массиву _x
+// и раскрывает свёртки sum/prod в reduce по диапазону.
+type variablesPatcher struct {
+	// узлы, полученные из одиночного x: если за ними следует индекс, это x[i]
+	scalars       map[ast.Node]bool
+	dimensions    int
+	timeDependent bool
+	err           error
+}
+
+func (p *variablesPatcher) Visit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.VariableDeclaratorNode:
+		// обращения к такому имени уже переписаны в координаты, и let был бы
+		// молча проигнорирован; так же проверяются имена вспомогательных функций
+		if _, ok := aliasIndices[n.Name]; ok || indexedVarRegexp.MatchString(n.Name) {
+			p.err = fmt.Errorf("имя %q в let совпадает с именем координаты", n.Name)
+		}
+
+	case *ast.IdentifierNode:
+		if n.Value == iterationName || n.Value == evaluationsName {
+			p.timeDependent = true
+		}
+		index, ok := aliasIndices[n.Value]
+		if !ok {
+			match := indexedVarRegexp.FindStringSubmatch(n.Value)
+			if match == nil {
+				return
+			}
+			number, _ := strconv.Atoi(match[1])
+			if number < 1 {
+				p.err = fmt.Errorf("переменная %s: нумерация координат начинается с 1", n.Value)
+				return
+			}
+			index = number - 1
+		}
+		p.require(index + 1)
+
+		replacement := &ast.MemberNode{
+			Node:     &ast.IdentifierNode{Value: varsName},
+			Property: &ast.IntegerNode{Value: index},
+		}
+		if n.Value == "x" {
+			p.scalars[replacement] = true
+		}
+		ast.Patch(node, replacement)
+
+	case *ast.MemberNode:
+		if !p.scalars[n.Node] {
+			return
+		}
+		// x[c] -> _x[c - 1] для постоянного c, иначе x[i] -> _x[_index(int(i), len(_x))]
+		if constant, ok := n.Property.(*ast.IntegerNode); ok {
+			if constant.Value < 1 {
+				p.err = fmt.Errorf("x[%d]: нумерация координат начинается с 1", constant.Value)
+				return
+			}
+			p.require(constant.Value)
+			ast.Patch(node, &ast.MemberNode{
+				Node:     &ast.IdentifierNode{Value: varsName},
+				Property: &ast.IntegerNode{Value: constant.Value - 1},
+			})
+			return
+		}
+		ast.Patch(node, &ast.MemberNode{
+			Node: &ast.IdentifierNode{Value: varsName},
+			Property: &ast.CallNode{
+				Callee: &ast.IdentifierNode{Value: indexName},
+				Arguments: []ast.Node{
+					&ast.BuiltinNode{Name: "int", Arguments: []ast.Node{n.Property}},
+					&ast.BuiltinNode{Name: "len", Arguments: []ast.Node{&ast.IdentifierNode{Value: varsName}}},
+				},
+			},
+		})
+
+	case *ast.CallNode:
+		callee, ok := n.Callee.(*ast.IdentifierNode)
+		if !ok {
+			return
+		}
+
+		var operator string
+		var initial float64
+		switch callee.Value {
+		case "sum":
+			operator, initial = "+", 0
+		case "prod":
+			operator, initial = "*", 1
+		default:
+			return
+		}
+
+		if len(n.Arguments) != 4 {
+			p.err = fmt.Errorf("%s ожидает аргументы (индекс, от, до, выражение)", callee.Value)
+			return
+		}
+		index, ok := n.Arguments[0].(*ast.IdentifierNode)
+		if !ok {
+			p.err = fmt.Errorf("%s: первым аргументом должно быть имя индекса", callee.Value)
+			return
+		}
+
+		// sum(i, a, b, body) -> reduce(int(a)..int(b), let i = float(#); #acc + body, 0)
+		ast.Patch(node, &ast.BuiltinNode{
+			Name: "reduce",
+			Arguments: []ast.Node{
+				&ast.BinaryNode{
+					Operator: "..",
+					Left:     &ast.BuiltinNode{Name: "int", Arguments: []ast.Node{n.Arguments[1]}},
+					Right:    &ast.BuiltinNode{Name: "int", Arguments: []ast.Node{n.Arguments[2]}},
+				},
+				&ast.PredicateNode{
+					Node: &ast.VariableDeclaratorNode{
+						Name:  index.Value,
+						Value: &ast.BuiltinNode{Name: "float", Arguments: []ast.Node{&ast.PointerNode{}}},
+						Expr: &ast.BinaryNode{
+							Operator: operator,
+							Left:     &ast.PointerNode{Name: "acc"},
+							Right:    n.Arguments[3],
+						},
+					},
+				},
+				&ast.FloatNode{Value: initial},
+			},
+		})
+	}
+}
+
+func (p *variablesPatcher) require(dimensions int) {
+	if dimensions > p.dimensions {
+		p.dimensions = dimensions
+	}
+}
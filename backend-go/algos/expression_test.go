@@ -0,0 +1,174 @@
+package algos
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/expr-lang/expr"
+)
+
+func TestExpressionIndex(t *testing.T) {
+	vars := []float64{1, 2, 3}
+	tests := []struct {
+		expression string
+		want       float64
+	}{
+		{"x + y + z", 6},
+		{"x1 * x3", 3},
+		{"x[1] + x[3]", 4},
+		{"sum(i, 1, n, x[i]^2)", 14},
+		{"prod(i, 2, 3, x[i])", 6},
+		// индекс вне 1..n — ошибка вычисления, а не чтение с конца массива
+		{"x[-1]", math.Inf(1)},
+		{"sum(i, 0, n, x[i])", math.Inf(1)},
+		{"sum(i, 1, n + 1, x[i])", math.Inf(1)},
+	}
+	for _, test := range tests {
+		e, err := CompileExpression(test.expression)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		if got := e.Eval(vars); got != test.want {
+			t.Errorf("%s = %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestExpressionIndexCompileErrors(t *testing.T) {
+	for _, expression := range []string{"x[0]", "x0 + y", "sum(1, 1, n, x)"} {
+		if _, err := CompileExpression(expression); err == nil {
+			t.Errorf("%s: ожидалась ошибка компиляции", expression)
+		}
+	}
+}
+
+// замыкания должны давать те же значения, что и виртуальная машина expr
+func TestClosureMatchesVM(t *testing.T) {
+	helpers, err := NewHelpers([]string{"r(a, b) = sqrt(a^2 + b^2)", "s = x + y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expressions := []string{
+		"(1-x)^2 + 100*(y-x^2)^2",
+		"x**3 - 2*y / z + 7 % 3",
+		"sin(x)*cos(y) + tan(z) + exp(-x) + log(abs(y)) + ln(2) + sqrt(abs(z))",
+		"asin(0.5) + acos(0.5) + atan(x) + atan2(y, x) + hypot(x, y) + pow(2, x)",
+		"sinh(x) + cosh(y) + tanh(z) + log2(8) + log10(100) + cbrt(z) + erf(x) + gamma(3.5)",
+		"floor(x) + ceil(y) + round(z) + sign(-x) + clamp(x, 0, 1) + min(x, y, z) + max(x, y) + min(z)",
+		"PI * E + n + t + evals",
+		"x > y ? x : y",
+		"x > 0 && y > 0 || !(z < 1) ? 1 : 2",
+		"if x == y { 1 } else { x != 0 ? -z : 0 }",
+		"let a = x + 1; let b = a * 2; a + b",
+		"let k = 2; x[k] + x[k + 1]",
+		"sum(i, 1, n, x[i]^2) + prod(i, 1, n, 1 + x[i])",
+		"sum(i, 1, n, sum(j, i, n, x[i] * x[j]))",
+		"sum(i, 2, 1, x[i])",
+		"x1 + x2 * x3 - x[2]",
+		"r(x, y) + s * r(z, 1)",
+		"-x + +y - -z",
+		"2 * 3 + 4 - 1 > 5 ? 10 / 4 : 0",
+	}
+	clock := &Clock{}
+	clock.iteration.Store(3)
+	clock.evaluations.Store(17)
+	points := [][]float64{{0.5, 1.5, 2.5}, {-1, 2, -3, 4}, {1, 1, 1}}
+	for _, expression := range expressions {
+		e, err := CompileExpressionWithHelpers(expression, helpers)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		if e.fast == nil {
+			t.Errorf("%s: выражение не переведено в замыкания", expression)
+			continue
+		}
+		e.clock = clock
+		for _, point := range points {
+			want, err := e.run(point)
+			if err != nil {
+				t.Fatalf("%s: %v", expression, err)
+			}
+			if math.IsNaN(want) {
+				want = math.Inf(1)
+			}
+			if got := e.Eval(point); got != want {
+				t.Errorf("%s в %v: замыкания дали %v, виртуальная машина %v", expression, point, got, want)
+			}
+		}
+	}
+}
+
+// rosenbrock в двух переменных: исходный вычислитель поддерживал только x и y
+const benchmarkExpression = "(1-x)^2 + 100*(y-x^2)^2 + sin(x)*cos(y)"
+
+// BenchmarkEvalBaseline — исходный вычислитель: одно общее окружение-словарь,
+// в которое перед каждым вызовом записываются координаты, и expr.Run
+func BenchmarkEvalBaseline(b *testing.B) {
+	env := map[string]any{"x": 0.0, "y": 0.0, "sin": math.Sin, "cos": math.Cos}
+	program, err := expr.Compile(benchmarkExpression, expr.Env(env))
+	if err != nil {
+		b.Fatal(err)
+	}
+	vars := []float64{0.5, 1.5}
+	for b.Loop() {
+		env["x"] = vars[0]
+		env["y"] = vars[1]
+		if _, err := expr.Run(program, env); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEval(b *testing.B) {
+	e, err := CompileExpression(benchmarkExpression)
+	if err != nil {
+		b.Fatal(err)
+	}
+	vars := []float64{0.5, 1.5}
+	for b.Loop() {
+		e.Eval(vars)
+	}
+}
+
+func BenchmarkEvalParallel(b *testing.B) {
+	e, err := CompileExpression(benchmarkExpression)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		vars := []float64{0.5, 1.5}
+		for pb.Next() {
+			e.Eval(vars)
+		}
+	})
+}
+
+func BenchmarkEvalSum(b *testing.B) {
+	e, err := CompileExpression("sum(i, 1, n, x[i]^2)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	vars := make([]float64, 30)
+	for b.Loop() {
+		e.Eval(vars)
+	}
+}
+
+// имя из let, совпадающее с координатой, было бы молча заменено координатой
+func TestLetCoordinateNames(t *testing.T) {
+	for _, expression := range []string{"let x = 5; x + y", "let y = x; y", "let x3 = 1; x3", "let a = 1; let z = a; z"} {
+		if _, err := CompileExpression(expression); err == nil || !strings.Contains(err.Error(), "совпадает с именем координаты") {
+			t.Errorf("%s: %v, ожидалась ошибка об имени координаты", expression, err)
+		}
+	}
+	e, err := CompileExpression("let x_ = 5; let xs = 2; x_ * xs + y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Eval([]float64{3, 4}); got != 14 {
+		t.Errorf("Eval = %v, want 14", got)
+	}
+}
@@ -0,0 +1,320 @@
+package algos
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+type ExternalRequest struct {
+	// имя программы из списка, разрешённого сервером
+	Program string `json:"program"`
+	// время ожидания ответа на один пакет, в секундах
+	Timeout *float64 `json:"timeout,omitempty"`
+	// число одновременно запущенных процессов
+	Concurrency *int `json:"concurrency,omitempty"`
+	// число перезапусков процесса при сбое в рамках одного пакета
+	Restarts *int `json:"restarts,omitempty"`
+}
+
+// ExternalProgram — внешняя программа, которую разрешено запускать. Запрос выбирает
+// программу по имени, поэтому клиент не может запустить произвольную команду.
+type ExternalProgram struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+var (
+	externalMu       sync.RWMutex
+	externalPrograms = map[string]ExternalProgram{}
+)
+
+// RegisterExternal разрешает запускать программу под именем name
+func RegisterExternal(name string, program ExternalProgram) error {
+	if name == "" {
+		return errors.New("имя внешней программы не задано")
+	}
+	if program.Command == "" {
+		return fmt.Errorf("внешняя программа %q: не задана команда", name)
+	}
+
+	externalMu.Lock()
+	defer externalMu.Unlock()
+	if _, ok := externalPrograms[name]; ok {
+		return fmt.Errorf("внешняя программа %q уже зарегистрирована", name)
+	}
+	externalPrograms[name] = program
+	return nil
+}
+
+// LoadExternalPrograms регистрирует программы из JSON-файла
+// вида {"имя": {"command": "python3", "args": ["sim.py"]}}
+func LoadExternalPrograms(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var programs map[string]ExternalProgram
+	if err := json.Unmarshal(data, &programs); err != nil {
+		return fmt.Errorf("ошибка чтения списка внешних программ: %w", err)
+	}
+	for name, program := range programs {
+		if err := RegisterExternal(name, program); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// External вычисляет целевую функцию во внешней программе.
+// Протокол — строки JSON: программа читает из stdin {"positions": [[...], ...]}
+// и отвечает в stdout {"values": [...]} или {"error": "..."}.
+type External struct {
+	Command     string
+	Args        []string
+	Timeout     time.Duration
+	Concurrency int
+	Restarts    int
+
+	// свободные процессы; nil — процесс ещё не запущен или упал
+	pool chan *externalProcess
+	// ожидание ответа прерывается при отмене работы алгоритма
+	clock *Clock
+	batchErrors
+}
+
+type externalProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// ошибка, о которой сообщила сама программа: перезапуск её не исправит
+type externalError struct {
+	message string
+}
+
+func (e externalError) Error() string {
+	return "внешняя программа вернула ошибку: " + e.message
+}
+
+func NewExternal(request ExternalRequest) (*External, error) {
+	externalMu.RLock()
+	program, ok := externalPrograms[request.Program]
+	externalMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("внешняя программа %q не разрешена сервером", request.Program)
+	}
+
+	external := &External{
+		Command:     program.Command,
+		Args:        program.Args,
+		Timeout:     time.Duration(setDefault(request.Timeout, 10.0) * float64(time.Second)),
+		Concurrency: setDefault(request.Concurrency, 1),
+		Restarts:    setDefault(request.Restarts, 3),
+	}
+
+	if _, err := exec.LookPath(external.Command); err != nil {
+		return nil, fmt.Errorf("внешняя программа %q не найдена", external.Command)
+	}
+	if external.Timeout <= 0 {
+		return nil, errors.New("время ожидания должно быть больше 0")
+	}
+	if external.Concurrency < 1 {
+		return nil, errors.New("число процессов должно быть больше 0")
+	}
+	if external.Restarts < 0 {
+		return nil, errors.New("число перезапусков должно быть неотрицательным")
+	}
+
+	// процессы запускаются при первом обращении
+	external.pool = make(chan *externalProcess, external.Concurrency)
+	for range external.Concurrency {
+		external.pool <- nil
+	}
+	return external, nil
+}
+
+func (e *External) start() (*externalProcess, error) {
+	cmd := exec.Command(e.Command, e.Args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("не удалось запустить внешнюю программу: %w", err)
+	}
+	return &externalProcess{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// время, за которое программа должна выйти после закрытия stdin
+var externalCloseTimeout = 5 * time.Second
+
+func (p *externalProcess) kill() {
+	p.cmd.Process.Kill()
+	p.cmd.Wait()
+}
+
+func (p *externalProcess) evaluate(ctx context.Context, positions [][]float64, timeout time.Duration) ([]float64, error) {
+	done := make(chan struct{})
+	var result batchResponse
+	var err error
+	go func() {
+		defer close(done)
+		line, _ := json.Marshal(batchRequest{Positions: positions})
+		if _, err = p.stdin.Write(append(line, '\n')); err != nil {
+			return
+		}
+		if line, err = p.stdout.ReadBytes('\n'); err != nil {
+			return
+		}
+		err = json.Unmarshal(line, &result)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		// процесс убивается, чтобы прервать чтение; пул запустит новый
+		p.kill()
+		<-done
+		return nil, fmt.Errorf("внешняя программа не ответила за %v", timeout)
+	case <-ctx.Done():
+		p.kill()
+		<-done
+		return nil, ctx.Err()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обмена с внешней программой: %w", err)
+	}
+	if result.Error != "" {
+		return nil, externalError{result.Error}
+	}
+	if len(result.Values) != len(positions) {
+		return nil, fmt.Errorf("внешняя программа вернула %d значений вместо %d", len(result.Values), len(positions))
+	}
+	return result.Values, nil
+}
+
+// EvaluateBatch вычисляет функцию в пакете точек одним обращением к свободному процессу;
+// упавший или зависший процесс перезапускается. При отмене работы алгоритма
+// ожидание свободного процесса прерывается, а занятый процесс убивается.
+func (e *External) EvaluateBatch(positions [][]float64) ([]float64, error) {
+	ctx := e.clock.runContext()
+	var process *externalProcess
+	select {
+	case process = <-e.pool:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { e.pool <- process }()
+
+	var err error
+	for range e.Restarts + 1 {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if process == nil {
+			if process, err = e.start(); err != nil {
+				return nil, err
+			}
+		}
+
+		var values []float64
+		values, err = process.evaluate(ctx, positions, e.Timeout)
+		if err == nil {
+			return values, nil
+		}
+		var programErr externalError
+		if errors.As(err, &programErr) {
+			return nil, err
+		}
+		process.kill()
+		process = nil
+	}
+	return nil, err
+}
+
+// Evaluate делит точки между процессами и вычисляет их пакетами;
+// при ошибке значения пакета — +Inf, как при ошибке вычисления выражения
+func (e *External) Evaluate(positions [][]float64) []float64 {
+	ctx := e.clock.runContext()
+	values := make([]float64, len(positions))
+	size := (len(positions) + e.Concurrency - 1) / e.Concurrency
+
+	var wg sync.WaitGroup
+	for start := 0; start < len(positions); start += size {
+		end := min(start+size, len(positions))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batch, err := e.EvaluateBatch(positions[start:end])
+			if err != nil {
+				// отмена — не ошибка целевой функции, о ней сообщает причина остановки
+				if ctx.Err() == nil {
+					e.record(err)
+				}
+				for i := start; i < end; i++ {
+					values[i] = math.Inf(1)
+				}
+				return
+			}
+			copy(values[start:end], batch)
+		}()
+	}
+	wg.Wait()
+
+	return values
+}
+
+func (e *External) Eval(position []float64) float64 {
+	return e.Evaluate([][]float64{position})[0]
+}
+
+// stop закрывает stdin и ждёт выхода программы; не вышедшая вовремя программа убивается
+func (p *externalProcess) stop() error {
+	p.stdin.Close()
+	done := make(chan error, 1)
+	go func() { done <- p.cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(externalCloseTimeout):
+		p.cmd.Process.Kill()
+		<-done
+		return fmt.Errorf("внешняя программа не завершилась за %v и была остановлена", externalCloseTimeout)
+	}
+}
+
+// Close завершает процессы: закрытый stdin — сигнал программе выйти
+func (e *External) Close() error {
+	processes := make([]*externalProcess, e.Concurrency)
+	for i := range processes {
+		processes[i] = <-e.pool
+	}
+
+	var err error
+	for _, process := range processes {
+		if process != nil {
+			if stopErr := process.stop(); stopErr != nil && err == nil {
+				err = stopErr
+			}
+		}
+		e.pool <- nil
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package algos
+
+import (
+	"context"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExternalAllowlist(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("нет sh")
+	}
+	path := filepath.Join(t.TempDir(), "programs.json")
+	programs := `{"test-echo": {"command": "sh", "args": ["-c", "while read line; do echo '{\"values\":[7]}'; done"]}}`
+	if err := os.WriteFile(path, []byte(programs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadExternalPrograms(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewExternal(ExternalRequest{Program: "sh"}); err == nil || !strings.Contains(err.Error(), "не разрешена") {
+		t.Errorf("запущена не разрешённая программа: %v", err)
+	}
+	if err := RegisterExternal("test-echo", ExternalProgram{Command: "sh"}); err == nil {
+		t.Error("программа зарегистрирована дважды")
+	}
+
+	external, err := NewExternal(ExternalRequest{Program: "test-echo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := external.Eval([]float64{1, 2}); got != 7 {
+		t.Errorf("Eval = %v, want 7", got)
+	}
+	if err := external.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+// программа, не вышедшая после закрытия stdin, убивается по истечении времени ожидания
+func TestExternalCloseKillsHungProgram(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("нет sh")
+	}
+	timeout := externalCloseTimeout
+	externalCloseTimeout = 100 * time.Millisecond
+	defer func() { externalCloseTimeout = timeout }()
+
+	// отвечает на первый пакет и больше не читает stdin
+	script := `read line; echo '{"values":[1]}'; exec sleep 30`
+	if err := RegisterExternal("test-hung", ExternalProgram{Command: "sh", Args: []string{"-c", script}}); err != nil {
+		t.Fatal(err)
+	}
+	external, err := NewExternal(ExternalRequest{Program: "test-hung"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	external.Eval([]float64{0})
+
+	start := time.Now()
+	if err := external.Close(); err == nil || !strings.Contains(err.Error(), "не завершилась") {
+		t.Errorf("Close = %v, ожидалась ошибка об остановке программы", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close ждал %v", elapsed)
+	}
+}
+
+// отмена работы алгоритма прерывает ожидание ответа зависшей программы
+// и ожидание свободного процесса; программа убивается
+func TestExternalCancel(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("нет sh")
+	}
+	if err := RegisterExternal("test-silent", ExternalProgram{Command: "sh", Args: []string{"-c", "exec sleep 30"}}); err != nil {
+		t.Fatal(err)
+	}
+	concurrency := 1
+	external, err := NewExternal(ExternalRequest{Program: "test-silent", Concurrency: &concurrency})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer external.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	external.clock = &Clock{ctx: ctx}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	// второй пакет ждёт единственный процесс, занятый первым
+	results := make([][]float64, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = external.Evaluate([][]float64{{float64(i)}})
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Evaluate после отмены ждал %v", elapsed)
+	}
+	for _, values := range results {
+		if !math.IsInf(values[0], 1) {
+			t.Errorf("Evaluate = %v, want +Inf", values)
+		}
+	}
+	if err := external.Err(); err != nil {
+		t.Errorf("отмена сохранена как ошибка функции: %v", err)
+	}
+}
@@ -0,0 +1,363 @@
+package algos
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/expr-lang/expr/vm"
+)
+
+type FitRequest struct {
+	// параметрическая модель, например a*exp(-b*t)+c
+	Model string `json:"model"`
+	// порядок параметров в векторе решения; по умолчанию — в порядке появления в модели
+	Parameters []string `json:"parameters,omitempty"`
+	// данные: CSV с заголовком или столбцы по именам
+	CSV  string               `json:"csv,omitempty"`
+	Data map[string][]float64 `json:"data,omitempty"`
+	// столбец с измеренными значениями
+	Target string `json:"target,omitempty"`
+	// столбец со стандартными отклонениями измерений для правдоподобия
+	Sigma string `json:"sigma,omitempty"`
+	Loss  string `json:"loss,omitempty"`
+}
+
+// функции потерь
+const (
+	MSELoss = "mse"
+	MAELoss = "mae"
+	NLLLoss = "nll" // отрицательное логарифмическое правдоподобие с нормальными ошибками
+)
+
+// наименьшая оценка дисперсии остатков в правдоподобии без заданных σ
+const minFitVariance = 1e-300
+
+// Fit — задача подбора параметров модели по данным. Остальные столбцы данных
+// доступны в модели как переменные со своими именами, поэтому t здесь —
+// столбец данных, а не номер итерации.
+type Fit struct {
+	Parameters []string
+	Target     string
+	Loss       string
+
+	program *vm.Program
+	states  sync.Pool
+	// значения переменных модели в каждой строке данных
+	rows   []map[string]float64
+	target []float64
+	sigma  []float64
+}
+
+type FitResult struct {
+	Parameters map[string]float64 `json:"parameters"`
+	Loss       float64            `json:"loss"`
+	// значения модели в точках данных
+	Fitted    []float64 `json:"fitted"`
+	Residuals []float64 `json:"residuals"`
+
+	MSE          float64 `json:"mse"`
+	RMSE         float64 `json:"rmse"`
+	MAE          float64 `json:"mae"`
+	MaxResidual  float64 `json:"maxResidual"`
+	MeanResidual float64 `json:"meanResidual"`
+	StdResidual  float64 `json:"stdResidual"`
+	RSquared     float64 `json:"rSquared"`
+}
+
+type fitState struct {
+	vm  vm.VM
+	env map[string]any
+}
+
+func NewFit(request FitRequest) (*Fit, error) {
+	fit := &Fit{
+		Target: request.Target,
+		Loss:   request.Loss,
+	}
+	if fit.Target == "" {
+		fit.Target = "y"
+	}
+	switch fit.Loss {
+	case "":
+		fit.Loss = MSELoss
+	case MSELoss, MAELoss, NLLLoss:
+	default:
+		return nil, fmt.Errorf("неизвестная функция потерь %q", request.Loss)
+	}
+
+	columns, err := fitColumns(request)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := columns[fit.Target]
+	if !ok {
+		return nil, fmt.Errorf("в данных нет столбца %q", fit.Target)
+	}
+	fit.target = target
+	delete(columns, fit.Target)
+
+	if request.Sigma != "" {
+		if fit.sigma, ok = columns[request.Sigma]; !ok {
+			return nil, fmt.Errorf("в данных нет столбца %q", request.Sigma)
+		}
+		if slices.ContainsFunc(fit.sigma, func(s float64) bool { return s <= 0 }) {
+			return nil, errors.New("стандартные отклонения измерений должны быть положительными")
+		}
+		delete(columns, request.Sigma)
+	}
+
+	env := mathEnv()
+	for name := range columns {
+		if _, ok := env[name]; ok {
+			return nil, fmt.Errorf("имя столбца %q совпадает с именем функции", name)
+		}
+		env[name] = 0.0
+	}
+
+	fit.Parameters = request.Parameters
+	if fit.Parameters == nil {
+		if fit.Parameters, err = modelParameters(request.Model, env); err != nil {
+			return nil, err
+		}
+	}
+	if len(fit.Parameters) == 0 {
+		return nil, errors.New("в модели нет параметров для подбора")
+	}
+	for _, name := range fit.Parameters {
+		if _, ok := env[name]; ok {
+			return nil, fmt.Errorf("параметр %q совпадает с именем столбца или функции", name)
+		}
+		env[name] = 0.0
+	}
+
+	fit.program, err = expr.Compile(request.Model, expr.Env(env), expr.AsFloat64())
+	if err != nil {
+		return nil, errors.New("Ошибка компиляции модели:" + err.Error())
+	}
+
+	fit.rows = make([]map[string]float64, len(fit.target))
+	for i := range fit.rows {
+		fit.rows[i] = make(map[string]float64, len(columns))
+		for name, column := range columns {
+			fit.rows[i][name] = column[i]
+		}
+	}
+
+	fit.states.New = func() any {
+		state := &fitState{env: mathEnv()}
+		for name := range columns {
+			state.env[name] = 0.0
+		}
+		return state
+	}
+	return fit, nil
+}
+
+// fitColumns читает данные из CSV или столбцов и проверяет их длины
+func fitColumns(request FitRequest) (map[string][]float64, error) {
+	columns := make(map[string][]float64)
+	switch {
+	case request.CSV != "" && request.Data != nil:
+		return nil, errors.New("данные заданы одновременно в CSV и по столбцам")
+	case request.CSV != "":
+		records, err := csv.NewReader(strings.NewReader(request.CSV)).ReadAll()
+		if err != nil {
+			return nil, errors.New("Ошибка чтения CSV:" + err.Error())
+		}
+		if len(records) < 2 {
+			return nil, errors.New("в CSV нет строк с данными")
+		}
+		header := records[0]
+		for j := range header {
+			header[j] = strings.TrimSpace(header[j])
+			columns[header[j]] = make([]float64, len(records)-1)
+		}
+		for i, record := range records[1:] {
+			for j, field := range record {
+				value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+				if err != nil {
+					return nil, fmt.Errorf("строка %d, столбец %q: %q не число", i+2, header[j], field)
+				}
+				columns[header[j]][i] = value
+			}
+		}
+	default:
+		for name, column := range request.Data {
+			columns[name] = column
+		}
+	}
+
+	rows := -1
+	for name, column := range columns {
+		if rows >= 0 && len(column) != rows {
+			return nil, fmt.Errorf("длина столбца %q отличается от остальных", name)
+		}
+		rows = len(column)
+	}
+	if rows < 1 {
+		return nil, errors.New("нет данных для подбора модели")
+	}
+	return columns, nil
+}
+
+// modelParameters находит в модели имена, не являющиеся столбцами данных и функциями
+func modelParameters(model string, env map[string]any) ([]string, error) {
+	tree, err := parser.Parse(model)
+	if err != nil {
+		return nil, errors.New("Ошибка компиляции модели:" + err.Error())
+	}
+	collector := &parametersCollector{env: env, declared: make(map[string]bool)}
+	ast.Walk(&tree.Node, collector)
+	// обход идёт снизу вверх, поэтому имена из let исключаются после него
+	return slices.DeleteFunc(collector.parameters, func(name string) bool {
+		return collector.declared[name]
+	}), nil
+}
+
+type parametersCollector struct {
+	env        map[string]any
+	declared   map[string]bool
+	parameters []string
+}
+
+func (c *parametersCollector) Visit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.VariableDeclaratorNode:
+		c.declared[n.Name] = true
+	case *ast.IdentifierNode:
+		if _, ok := c.env[n.Value]; ok || slices.Contains(c.parameters, n.Value) {
+			return
+		}
+		c.parameters = append(c.parameters, n.Value)
+	}
+}
+
+// Predict вычисляет модель во всех точках данных
+func (f *Fit) Predict(parameters []float64) []float64 {
+	state := f.states.Get().(*fitState)
+	defer f.states.Put(state)
+
+	for i, name := range f.Parameters {
+		state.env[name] = parameters[i]
+	}
+	predicted := make([]float64, len(f.rows))
+	for i, row := range f.rows {
+		for name, value := range row {
+			state.env[name] = value
+		}
+		output, err := state.vm.Run(f.program, state.env)
+		if err != nil {
+			predicted[i] = math.NaN()
+			continue
+		}
+		predicted[i] = output.(float64)
+	}
+	return predicted
+}
+
+// Func — значение функции потерь при заданных параметрах
+func (f *Fit) Func(parameters []float64) float64 {
+	return f.loss(f.residuals(f.Predict(parameters)))
+}
+
+func (f *Fit) residuals(predicted []float64) []float64 {
+	residuals := make([]float64, len(predicted))
+	for i := range predicted {
+		residuals[i] = f.target[i] - predicted[i]
+	}
+	return residuals
+}
+
+func (f *Fit) loss(residuals []float64) float64 {
+	n := float64(len(residuals))
+	value := 0.0
+	switch f.Loss {
+	case MAELoss:
+		for _, r := range residuals {
+			value += math.Abs(r)
+		}
+		value /= n
+
+	case NLLLoss:
+		if f.sigma == nil {
+			// σ неизвестна: подставляется её оценка максимального правдоподобия
+			mse := 0.0
+			for _, r := range residuals {
+				mse += r * r
+			}
+			// при точном совпадении модели с данными правдоподобие не ограничено,
+			// и потери были бы -Inf, которые не передать в JSON
+			mse = math.Max(mse/n, minFitVariance)
+			value = n / 2 * (math.Log(2*math.Pi*mse) + 1)
+			break
+		}
+		for i, r := range residuals {
+			s := f.sigma[i]
+			value += 0.5*math.Log(2*math.Pi*s*s) + r*r/(2*s*s)
+		}
+
+	default:
+		for _, r := range residuals {
+			value += r * r
+		}
+		value /= n
+	}
+
+	if math.IsNaN(value) {
+		return math.Inf(1)
+	}
+	return value
+}
+
+// Result возвращает подобранную кривую и статистику остатков
+func (f *Fit) Result(parameters []float64) *FitResult {
+	fitted := f.Predict(parameters)
+	residuals := f.residuals(fitted)
+	n := float64(len(residuals))
+
+	result := &FitResult{
+		Parameters: make(map[string]float64, len(f.Parameters)),
+		Loss:       f.loss(residuals),
+		Fitted:     fitted,
+		Residuals:  residuals,
+	}
+	for i, name := range f.Parameters {
+		result.Parameters[name] = parameters[i]
+	}
+
+	squares := 0.0
+	for _, r := range residuals {
+		result.MeanResidual += r / n
+		result.MAE += math.Abs(r) / n
+		result.MaxResidual = math.Max(result.MaxResidual, math.Abs(r))
+		squares += r * r
+	}
+	result.MSE = squares / n
+	result.RMSE = math.Sqrt(result.MSE)
+	for _, r := range residuals {
+		result.StdResidual += (r - result.MeanResidual) * (r - result.MeanResidual) / n
+	}
+	result.StdResidual = math.Sqrt(result.StdResidual)
+
+	mean := 0.0
+	for _, y := range f.target {
+		mean += y / n
+	}
+	total := 0.0
+	for _, y := range f.target {
+		total += (y - mean) * (y - mean)
+	}
+	if total > 0 {
+		result.RSquared = 1 - squares/total
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+package algos
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// при точном совпадении модели с данными потери правдоподобия конечны,
+// и ответ алгоритма кодируется в JSON
+func TestFitPerfectLikelihood(t *testing.T) {
+	fit := &FitRequest{Model: "a*t + b", Data: map[string][]float64{"t": {0, 1, 2}, "y": {1, 3, 5}}, Loss: NLLLoss}
+	gwo, err := NewGWO(GWORequest{AlgoRequest: AlgoRequest{
+		Fit: fit, Iterations: 2, Population: [][]float64{{2, 1}, {0, 0}, {1, 1}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gwo.Close()
+	response := gwo.Init(context.Background())
+	if math.IsInf(response.BestValue, 0) || math.IsNaN(response.BestValue) {
+		t.Errorf("лучшее значение %v", response.BestValue)
+	}
+	if _, err := json.Marshal(response); err != nil {
+		t.Errorf("ответ не кодируется в JSON: %v", err)
+	}
+}
+
+func TestFitDataErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		request FitRequest
+		want    string
+	}{
+		{"разная длина столбцов", FitRequest{Data: map[string][]float64{"t": {0, 1}, "y": {1, 2, 3}}}, "отличается от остальных"},
+		{"столбец с именем функции", FitRequest{Data: map[string][]float64{"sin": {0, 1}, "y": {1, 2}}}, "совпадает с именем функции"},
+		{"нет столбца измерений", FitRequest{Data: map[string][]float64{"t": {0, 1}, "z": {1, 2}}}, `нет столбца "y"`},
+		{"нет заданного столбца измерений", FitRequest{Data: map[string][]float64{"t": {0, 1}, "y": {1, 2}}, Target: "v"}, `нет столбца "v"`},
+		{"нет столбца σ", FitRequest{Data: map[string][]float64{"t": {0, 1}, "y": {1, 2}}, Sigma: "s"}, `нет столбца "s"`},
+		{"неположительная σ", FitRequest{Data: map[string][]float64{"t": {0, 1}, "y": {1, 2}, "s": {1, 0}}, Sigma: "s"}, "должны быть положительными"},
+		{"пустые столбцы", FitRequest{Data: map[string][]float64{"t": {}, "y": {}}}, "нет данных"},
+		{"CSV и столбцы одновременно", FitRequest{CSV: "t,y\n0,1", Data: map[string][]float64{"t": {0}, "y": {1}}}, "одновременно"},
+		{"CSV без строк данных", FitRequest{CSV: "t,y\n"}, "нет строк"},
+		{"не число в CSV", FitRequest{CSV: "t,y\n0,1\n1,abc"}, `строка 3, столбец "y"`},
+		{"разное число полей в CSV", FitRequest{CSV: "t,y\n0,1\n1"}, "Ошибка чтения CSV"},
+		{"неизвестная функция потерь", FitRequest{Data: map[string][]float64{"t": {0}, "y": {1}}, Loss: "huber"}, "неизвестная функция потерь"},
+		{"параметр совпадает со столбцом", FitRequest{Data: map[string][]float64{"t": {0}, "y": {1}}, Parameters: []string{"t"}}, "совпадает с именем столбца"},
+	}
+	for _, test := range tests {
+		if test.request.Model == "" {
+			test.request.Model = "a*t + b"
+		}
+		if _, err := NewFit(test.request); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: %v, ожидалась ошибка %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestFitParameters(t *testing.T) {
+	data := map[string][]float64{"t": {0, 1}, "y": {1, 2}}
+	tests := []struct {
+		model      string
+		parameters []string
+		want       []string
+	}{
+		{"a*exp(-b*t) + c", nil, []string{"a", "b", "c"}},
+		{"c + b*t + a*t^2", nil, []string{"c", "b", "a"}},
+		{"a*t + a", nil, []string{"a"}},
+		{"let k = a*t; k + b", nil, []string{"a", "b"}},
+		{"let k = a; let m = k*t; m + k*b", nil, []string{"a", "b"}},
+		{"a*t + b", []string{"b", "a"}, []string{"b", "a"}},
+	}
+	for _, test := range tests {
+		fit, err := NewFit(FitRequest{Model: test.model, Data: data, Parameters: test.parameters})
+		if err != nil {
+			t.Errorf("%s: %v", test.model, err)
+			continue
+		}
+		if !slices.Equal(fit.Parameters, test.want) {
+			t.Errorf("%s: параметры %v, want %v", test.model, fit.Parameters, test.want)
+		}
+	}
+
+	if _, err := NewFit(FitRequest{Model: "2*t", Data: data}); err == nil {
+		t.Error("модель без параметров: ожидалась ошибка")
+	}
+}
+
+func TestFitLoss(t *testing.T) {
+	data := map[string][]float64{"t": {0, 1, 2}, "y": {1, 3, 5}, "s": {1, 1, 2}}
+	logTwoPi := math.Log(2 * math.Pi)
+	tests := []struct {
+		name       string
+		loss       string
+		sigma      string
+		parameters []float64
+		want       float64
+	}{
+		// остатки [1 1 1]
+		{"MSE", MSELoss, "", []float64{2, 0}, 1},
+		{"MAE", MAELoss, "", []float64{2, 0}, 1},
+		{"NLL с оценкой σ", NLLLoss, "", []float64{2, 0}, 1.5 * (logTwoPi + 1)},
+		{"NLL с заданными σ", NLLLoss, "s", []float64{2, 0}, 1.5*logTwoPi + 0.5 + 0.5 + 0.5*math.Log(4) + 1.0/8},
+		// остатки [0 1 2]
+		{"MSE по умолчанию", "", "", []float64{1, 1}, 5.0 / 3},
+		{"MAE с разными остатками", MAELoss, "", []float64{1, 1}, 1},
+		{"NLL с разными остатками", NLLLoss, "", []float64{1, 1}, 1.5 * (math.Log(2*math.Pi*5/3) + 1)},
+		// точное совпадение
+		{"NLL без остатков", NLLLoss, "", []float64{2, 1}, 1.5 * (math.Log(2*math.Pi*minFitVariance) + 1)},
+	}
+	for _, test := range tests {
+		fit, err := NewFit(FitRequest{Model: "a*t + b", Data: data, Target: "y", Sigma: test.sigma, Loss: test.loss})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if test.sigma == "" {
+			// столбец σ, не выбранный как σ, остаётся переменной модели
+			if _, ok := fit.rows[0]["s"]; !ok {
+				t.Errorf("%s: столбец s недоступен в модели", test.name)
+			}
+		}
+		if got := fit.Func(test.parameters); math.Abs(got-test.want) > 1e-9*math.Max(1, math.Abs(test.want)) {
+			t.Errorf("%s: потери %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// ошибка вычисления модели даёт +Inf, а не NaN
+	fit, err := NewFit(FitRequest{Model: "a*t + b", Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fit.Func([]float64{math.NaN(), 0}); !math.IsInf(got, 1) {
+		t.Errorf("потери при NaN = %v, want +Inf", got)
+	}
+}
+
+func TestFitResult(t *testing.T) {
+	fit, err := NewFit(FitRequest{Model: "a*t + b", CSV: "t, y\n0, 1\n1, 3\n2, 5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// остатки [0 1 2], среднее измерений 3, полная сумма квадратов 8
+	result := fit.Result([]float64{1, 1})
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"a", result.Parameters["a"], 1},
+		{"b", result.Parameters["b"], 1},
+		{"потери", result.Loss, 5.0 / 3},
+		{"MSE", result.MSE, 5.0 / 3},
+		{"RMSE", result.RMSE, math.Sqrt(5.0 / 3)},
+		{"MAE", result.MAE, 1},
+		{"наибольший остаток", result.MaxResidual, 2},
+		{"средний остаток", result.MeanResidual, 1},
+		{"СКО остатков", result.StdResidual, math.Sqrt(2.0 / 3)},
+		{"R²", result.RSquared, 1 - (5.0/3*3)/8},
+	}
+	for _, test := range tests {
+		if math.Abs(test.got-test.want) > 1e-12 {
+			t.Errorf("%s = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+	if !slices.Equal(result.Fitted, []float64{1, 2, 3}) || !slices.Equal(result.Residuals, []float64{0, 1, 2}) {
+		t.Errorf("модель %v, остатки %v", result.Fitted, result.Residuals)
+	}
+}
@@ -1,5 +1,10 @@
 package algos
 
+import (
+	"runtime"
+	"sync"
+)
+
 func setDefault[T any](value *T, defaultValue T) T {
 	if value == nil {
 		return defaultValue
@@ -11,5 +16,86 @@ type Response struct {
 	StepPositions [][]float64 `json:"stepPositions,omitempty"`
 	BestPosition  []float64   `json:"bestPosition,omitempty"`
 	BestValue     float64     `json:"bestValue"`
-	Iteration     int         `json:"iteration"`
+	// названия выбранных категорий для категориальных координат BestPosition
+	BestLabels []string `json:"bestLabels,omitempty"`
+	Iteration  int      `json:"iteration"`
+	// число вычислений целевой функции с начала работы алгоритма
+	Evaluations  int          `json:"evaluations"`
+	OptimumError *float64     `json:"optimumError,omitempty"`
+	Violation    *float64     `json:"violation,omitempty"`
+	Feasible     *bool        `json:"feasible,omitempty"`
+	ParetoFront  *ParetoFront `json:"paretoFront,omitempty"`
+	// значение функции без шума в BestPosition
+	ExactBestValue *float64 `json:"exactBestValue,omitempty"`
+	// на этой итерации обнаружено изменение целевой функции
+	ChangeDetected bool `json:"changeDetected,omitempty"`
+	// подобранная модель и статистика остатков (в последнем ответе)
+	Fit *FitResult `json:"fit,omitempty"`
+	// причина остановки и итог работы (в последнем ответе)
+	StopReason string `json:"stopReason,omitempty"`
+	Summary    string `json:"summary,omitempty"`
+	// ошибка внешнего вычислителя целевой функции с предыдущего ответа
+	Error string `json:"error,omitempty"`
+}
+
+// BatchObjective вычисляет целевую функцию сразу в нескольких точках
+// во внешней системе; при ошибке значения — +Inf
+type BatchObjective interface {
+	Evaluate(positions [][]float64) []float64
+	Eval(position []float64) float64
+	// Err возвращает первую ошибку вычисления с прошлого вызова и сбрасывает её
+	Err() error
+	Close() error
+}
+
+// batchErrors запоминает ошибки вычислителя для ответа алгоритма
+type batchErrors struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (b *batchErrors) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *batchErrors) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// формат обмена с внешними вычислителями
+type batchRequest struct {
+	Positions [][]float64 `json:"positions"`
+}
+
+type batchResponse struct {
+	Values []float64 `json:"values"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// evaluateParallel вычисляет функцию во всех точках, распределяя их между горутинами
+func evaluateParallel(function func([]float64) float64, positions [][]float64) []float64 {
+	values := make([]float64, len(positions))
+	workers := min(runtime.GOMAXPROCS(0), len(positions))
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < len(positions); i += workers {
+				values[i] = function(positions[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return values
 }
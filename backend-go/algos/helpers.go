@@ -0,0 +1,165 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+)
+
+// Helpers — вспомогательные функции и именованные подвыражения, объявленные
+// в запросе строками вида "r(a, b) = sqrt(a^2 + b^2)" или "s = x + y".
+// Вызовы подставляются в выражение до компиляции.
+type Helpers map[string]helper
+
+type helper struct {
+	params []string
+	body   string
+}
+
+var helperRegexp = regexp.MustCompile(`^\s*([A-Za-z_]\w*)\s*(?:\(([^)]*)\))?\s*=\s*(.+)$`)
+
+// глубина подстановки, после которой определение считается рекурсивным
+const maxHelperDepth = 32
+
+func NewHelpers(definitions []string) (Helpers, error) {
+	helpers := make(Helpers, len(definitions))
+	reserved := newExpressionEnv()
+	for _, definition := range definitions {
+		match := helperRegexp.FindStringSubmatch(definition)
+		if match == nil {
+			return nil, fmt.Errorf("неверное определение функции %q: ожидается имя(параметры) = выражение", definition)
+		}
+
+		name := match[1]
+		if _, ok := reserved[name]; ok {
+			return nil, fmt.Errorf("имя %q уже занято встроенной функцией или переменной", name)
+		}
+		if _, ok := aliasIndices[name]; ok || indexedVarRegexp.MatchString(name) {
+			return nil, fmt.Errorf("имя %q совпадает с именем координаты", name)
+		}
+		if _, ok := helpers[name]; ok {
+			return nil, fmt.Errorf("функция %q объявлена дважды", name)
+		}
+
+		var params []string
+		if strings.TrimSpace(match[2]) != "" {
+			for _, param := range strings.Split(match[2], ",") {
+				params = append(params, strings.TrimSpace(param))
+			}
+		}
+		if _, err := parser.Parse(match[3]); err != nil {
+			return nil, errors.New("Ошибка компиляции функции " + name + ":" + err.Error())
+		}
+		helpers[name] = helper{params: params, body: match[3]}
+	}
+	return helpers, nil
+}
+
+// helpersPatcher подставляет тела вспомогательных функций: h(u, v) -> let _h1 = u; let _h2 = v; тело
+type helpersPatcher struct {
+	helpers Helpers
+	// тела функций без параметров, подставленные вместо имени: если имя
+	// было вызвано, f() -> тело
+	expanded map[ast.Node]string
+	// счётчик для уникальных имён параметров
+	counter int
+	depth   int
+	err     error
+}
+
+func (p *helpersPatcher) Visit(node *ast.Node) {
+	if p.err != nil {
+		return
+	}
+
+	var name string
+	var args []ast.Node
+	switch n := (*node).(type) {
+	case *ast.IdentifierNode:
+		name = n.Value
+	case *ast.CallNode:
+		if name, ok := p.expanded[n.Callee]; ok {
+			if len(n.Arguments) > 0 {
+				p.err = fmt.Errorf("%s ожидает 0 аргументов, передано %d", name, len(n.Arguments))
+				return
+			}
+			ast.Patch(node, n.Callee)
+			return
+		}
+		callee, ok := n.Callee.(*ast.IdentifierNode)
+		if !ok {
+			return
+		}
+		name, args = callee.Value, n.Arguments
+	default:
+		return
+	}
+
+	h, ok := p.helpers[name]
+	if !ok {
+		return
+	}
+	if _, call := (*node).(*ast.CallNode); !call && len(h.params) > 0 {
+		// имя функции без вызова — например, аргумент другой функции
+		return
+	}
+	if len(args) != len(h.params) {
+		p.err = fmt.Errorf("%s ожидает %d аргументов, передано %d", name, len(h.params), len(args))
+		return
+	}
+
+	if p.depth >= maxHelperDepth {
+		p.err = fmt.Errorf("функция %s определена рекурсивно", name)
+		return
+	}
+
+	tree, err := parser.Parse(h.body)
+	if err != nil {
+		p.err = err
+		return
+	}
+
+	// параметры переименовываются, чтобы не пересекаться с координатами и внешними let
+	renamed := make(map[string]string, len(h.params))
+	for _, param := range h.params {
+		p.counter++
+		renamed[param] = fmt.Sprintf("_h%d", p.counter)
+	}
+	ast.Walk(&tree.Node, &renamePatcher{names: renamed})
+
+	p.depth++
+	ast.Walk(&tree.Node, p)
+	p.depth--
+
+	body := tree.Node
+	for i := len(h.params) - 1; i >= 0; i-- {
+		body = &ast.VariableDeclaratorNode{
+			Name:  renamed[h.params[i]],
+			Value: &ast.BuiltinNode{Name: "float", Arguments: []ast.Node{args[i]}},
+			Expr:  body,
+		}
+	}
+	if _, call := (*node).(*ast.CallNode); !call {
+		if p.expanded == nil {
+			p.expanded = make(map[ast.Node]string)
+		}
+		p.expanded[body] = name
+	}
+	ast.Patch(node, body)
+}
+
+type renamePatcher struct {
+	names map[string]string
+}
+
+func (p *renamePatcher) Visit(node *ast.Node) {
+	if n, ok := (*node).(*ast.IdentifierNode); ok {
+		if name, ok := p.names[n.Value]; ok {
+			ast.Patch(node, &ast.IdentifierNode{Value: name})
+		}
+	}
+}
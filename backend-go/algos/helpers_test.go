@@ -0,0 +1,95 @@
+package algos
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHelpers(t *testing.T) {
+	helpers, err := NewHelpers([]string{
+		"r(a, b) = sqrt(a^2 + b^2)",
+		"sq(x) = x^2",
+		"twice(a) = 2 * a",
+		"quad(a) = twice(twice(a))",
+		"s = x + y",
+		"unit() = 1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expression string
+		want       float64
+	}{
+		{"r(x, y)", 5},
+		// параметр x функции sq — не координата: sq(y) = y²
+		{"sq(y)", 16},
+		{"sq(x) + sq(y)", 25},
+		{"quad(x)", 12},
+		// имена параметров не пересекаются с let вызывающего выражения
+		{"let a = 10; twice(x) + a", 16},
+		{"let a = 10; twice(a)", 20},
+		{"s * 2", 14},
+		{"s + unit() + unit", 9},
+		{"r(sq(x), s)", math.Sqrt(81 + 49)},
+	}
+	for _, test := range tests {
+		e, err := CompileExpressionWithHelpers(test.expression, helpers)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		if got := e.Eval([]float64{3, 4}); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s = %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestHelperDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		definitions []string
+		want        string
+	}{
+		{[]string{"r(a, b) sqrt(a)"}, "неверное определение"},
+		{[]string{"sin(a) = a"}, "уже занято"},
+		{[]string{"t = 1"}, "уже занято"},
+		{[]string{"x = 1"}, "совпадает с именем координаты"},
+		{[]string{"x2(a) = a"}, "совпадает с именем координаты"},
+		{[]string{"f(a) = a", "f(b) = b"}, "объявлена дважды"},
+		{[]string{"f(a) = a +"}, "Ошибка компиляции функции f"},
+	}
+	for _, test := range tests {
+		if _, err := NewHelpers(test.definitions); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%v: %v, ожидалась ошибка %q", test.definitions, err, test.want)
+		}
+	}
+}
+
+func TestHelperCallErrors(t *testing.T) {
+	helpers, err := NewHelpers([]string{
+		"r(a, b) = sqrt(a^2 + b^2)",
+		"f(a) = g(a) + 1",
+		"g(a) = f(a)",
+		"loop = loop + 1",
+		"unit() = 1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{"r(x)", "ожидает 2 аргументов, передано 1"},
+		{"r(x, y, 1)", "ожидает 2 аргументов, передано 3"},
+		{"f(x)", "определена рекурсивно"},
+		{"loop", "определена рекурсивно"},
+		{"unit(1)", "ожидает 0 аргументов, передано 1"},
+	}
+	for _, test := range tests {
+		if _, err := CompileExpressionWithHelpers(test.expression, helpers); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: %v, ожидалась ошибка %q", test.expression, err, test.want)
+		}
+	}
+}
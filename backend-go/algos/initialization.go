@@ -0,0 +1,303 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+type InitializationRequest struct {
+	// uniform, lhs, sobol, halton, opposition, gaussian или previous
+	Method string `json:"method,omitempty"`
+	// центр гауссова облака
+	Center []float64 `json:"center,omitempty"`
+	// стандартное отклонение облака: одно для всех координат или по одному на координату;
+	// по умолчанию — десятая часть ширины области поиска
+	Sigma []float64 `json:"sigma,omitempty"`
+	// итоговая популяция предыдущего запуска; недостающие агенты добавляются равномерно
+	Population [][]float64 `json:"population,omitempty"`
+	// число пропускаемых точек последовательностей Соболя и Холтона
+	Skip *int `json:"skip,omitempty"`
+}
+
+// способы создания начальной популяции
+const (
+	UniformInitialization    = "uniform"
+	LatinHypercube           = "lhs"
+	SobolInitialization      = "sobol"
+	HaltonInitialization     = "halton"
+	OppositionInitialization = "opposition" // лучшие из равномерных точек и противоположных им
+	GaussianInitialization   = "gaussian"
+	PreviousInitialization   = "previous"
+)
+
+type Initialization struct {
+	Method     string
+	Center     []float64
+	Sigma      []float64
+	Population [][]float64
+	Skip       int
+}
+
+func NewInitialization(request InitializationRequest, bounds [][]float64) (*Initialization, error) {
+	initialization := &Initialization{
+		Method: request.Method,
+		Skip:   setDefault(request.Skip, 0),
+	}
+	n := len(bounds)
+	if initialization.Skip < 0 {
+		return nil, errors.New("число пропускаемых точек не может быть отрицательным")
+	}
+
+	switch initialization.Method {
+	case "":
+		initialization.Method = UniformInitialization
+	case UniformInitialization, LatinHypercube, HaltonInitialization, OppositionInitialization:
+	case SobolInitialization:
+		if n > len(sobolParameters)+1 {
+			return nil, fmt.Errorf("последовательность Соболя поддерживается для размерности до %d", len(sobolParameters)+1)
+		}
+	case GaussianInitialization:
+		if len(request.Center) != n {
+			return nil, errors.New("несоответствие размерности центра облака и размерности задачи")
+		}
+		initialization.Center = request.Center
+		initialization.Sigma = make([]float64, n)
+		for j, bound := range bounds {
+			switch len(request.Sigma) {
+			case 0:
+				initialization.Sigma[j] = 0.1 * (bound[1] - bound[0])
+			case 1:
+				initialization.Sigma[j] = request.Sigma[0]
+			case n:
+				initialization.Sigma[j] = request.Sigma[j]
+			default:
+				return nil, errors.New("отклонение облака задаётся одним числом или для каждой координаты")
+			}
+			if initialization.Sigma[j] < 0 {
+				return nil, errors.New("отклонение облака не может быть отрицательным")
+			}
+		}
+	case PreviousInitialization:
+		if len(request.Population) == 0 {
+			return nil, errors.New("не задана популяция предыдущего запуска")
+		}
+		for _, position := range request.Population {
+			if len(position) != n {
+				return nil, errors.New("несоответствие размерности популяции предыдущего запуска и размерности задачи")
+			}
+		}
+		initialization.Population = request.Population
+	default:
+		return nil, fmt.Errorf("неизвестный способ создания популяции %q", request.Method)
+	}
+	return initialization, nil
+}
+
+// samplePopulation создаёт size точек начальной популяции в границах поиска
+func (algo *Algo) samplePopulation(initialization *Initialization, size int) [][]float64 {
+	population := make([][]float64, size)
+	switch initialization.Method {
+	case LatinHypercube:
+		// по каждой координате в каждый из size слоёв попадает ровно одна точка
+		for i := range population {
+			population[i] = make([]float64, algo.NumDimensions)
+		}
+		for j, bound := range algo.Bounds {
+			for i, stratum := range algo.Rng.Perm(size) {
+				u := (float64(stratum) + algo.Rng.Float64()) / float64(size)
+				population[i][j] = bound[0] + u*(bound[1]-bound[0])
+			}
+		}
+
+	case SobolInitialization, HaltonInitialization:
+		sequence := halton
+		if initialization.Method == SobolInitialization {
+			sequence = newSobol(algo.NumDimensions).point
+		}
+		for i := range population {
+			// первая точка обеих последовательностей — угол области, она пропускается
+			u := sequence(initialization.Skip+i+1, algo.NumDimensions)
+			population[i] = make([]float64, algo.NumDimensions)
+			for j, bound := range algo.Bounds {
+				population[i][j] = bound[0] + u[j]*(bound[1]-bound[0])
+			}
+		}
+
+	case GaussianInitialization:
+		for i := range population {
+			population[i] = make([]float64, algo.NumDimensions)
+			for j := range population[i] {
+				population[i][j] = initialization.Center[j] + initialization.Sigma[j]*algo.Rng.NormFloat64()
+			}
+		}
+
+	case PreviousInitialization:
+		for i := range population {
+			if i < len(initialization.Population) {
+				population[i] = slices.Clone(initialization.Population[i])
+			} else {
+				population[i] = algo.randomPosition()
+			}
+		}
+
+	default:
+		for i := range population {
+			population[i] = algo.randomPosition()
+		}
+	}
+
+	for _, position := range population {
+		algo.repair(position)
+	}
+	return population
+}
+
+// opposite — точка, симметричная данной относительно центра области поиска
+func (algo *Algo) opposite(position []float64) []float64 {
+	result := make([]float64, len(position))
+	for j, bound := range algo.Bounds {
+		result[j] = bound[0] + bound[1] - position[j]
+	}
+	return algo.repair(result)
+}
+
+// fittest оставляет size точек с наименьшими значениями
+func fittest(positions [][]float64, values []float64, size int) ([][]float64, []float64) {
+	order := make([]int, len(positions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return values[order[a]] < values[order[b]] || (math.IsNaN(values[order[b]]) && !math.IsNaN(values[order[a]]))
+	})
+	selected, selectedValues := make([][]float64, size), make([]float64, size)
+	for i, k := range order[:size] {
+		selected[i], selectedValues[i] = positions[k], values[k]
+	}
+	return selected, selectedValues
+}
+
+// halton возвращает точку последовательности Холтона с номером index
+// по первым простым основаниям
+func halton(index, dimensions int) []float64 {
+	point := make([]float64, dimensions)
+	for j, base := range primes(dimensions) {
+		f, result := 1.0, 0.0
+		for i := index; i > 0; i /= base {
+			f /= float64(base)
+			result += f * float64(i%base)
+		}
+		point[j] = result
+	}
+	return point
+}
+
+// primes возвращает первые count простых чисел
+func primes(count int) []int {
+	result := make([]int, 0, count)
+	for candidate := 2; len(result) < count; candidate++ {
+		prime := true
+		for _, p := range result {
+			if p*p > candidate {
+				break
+			}
+			if candidate%p == 0 {
+				prime = false
+				break
+			}
+		}
+		if prime {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+const sobolBits = 32
+
+// sobol — последовательность Соболя с направляющими числами Джо и Куо
+type sobol struct {
+	directions [][sobolBits]uint32
+}
+
+// sobolParameters — степень s, коэффициенты a примитивного многочлена и начальные
+// числа m для координат со второй (Joe, Kuo, new-joe-kuo-6.21201)
+var sobolParameters = []struct {
+	s, a int
+	m    []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+	{3, 2, []uint32{1, 1, 1}},
+	{4, 1, []uint32{1, 1, 3, 3}},
+	{4, 4, []uint32{1, 3, 5, 13}},
+	{5, 2, []uint32{1, 1, 5, 5, 17}},
+	{5, 4, []uint32{1, 1, 5, 5, 5}},
+	{5, 7, []uint32{1, 1, 7, 11, 19}},
+	{5, 11, []uint32{1, 1, 5, 1, 1}},
+	{5, 13, []uint32{1, 1, 1, 3, 11}},
+	{5, 14, []uint32{1, 3, 5, 5, 31}},
+	{6, 1, []uint32{1, 3, 3, 9, 7, 49}},
+	{6, 13, []uint32{1, 1, 1, 15, 21, 21}},
+	{6, 16, []uint32{1, 3, 1, 13, 27, 49}},
+	{6, 19, []uint32{1, 1, 1, 15, 7, 5}},
+	{6, 22, []uint32{1, 3, 1, 15, 13, 25}},
+	{6, 25, []uint32{1, 1, 5, 5, 19, 61}},
+	{7, 1, []uint32{1, 3, 7, 11, 23, 15, 103}},
+	{7, 4, []uint32{1, 3, 7, 13, 13, 15, 69}},
+	{7, 7, []uint32{1, 1, 3, 13, 7, 35, 63}},
+	{7, 8, []uint32{1, 3, 5, 9, 1, 25, 53}},
+	{7, 14, []uint32{1, 3, 1, 13, 9, 35, 107}},
+	{7, 19, []uint32{1, 3, 1, 5, 27, 61, 31}},
+	{7, 21, []uint32{1, 1, 5, 11, 19, 41, 61}},
+	{7, 28, []uint32{1, 3, 5, 3, 3, 13, 69}},
+	{7, 31, []uint32{1, 1, 7, 13, 1, 19, 1}},
+	{7, 32, []uint32{1, 3, 7, 5, 13, 19, 59}},
+	{7, 37, []uint32{1, 1, 3, 9, 25, 29, 41}},
+	{7, 41, []uint32{1, 3, 5, 13, 23, 1, 55}},
+	{7, 42, []uint32{1, 3, 7, 3, 13, 59, 17}},
+}
+
+func newSobol(dimensions int) *sobol {
+	s := &sobol{directions: make([][sobolBits]uint32, dimensions)}
+	for k := range sobolBits {
+		s.directions[0][k] = 1 << (sobolBits - 1 - k)
+	}
+	for j := 1; j < dimensions; j++ {
+		p := sobolParameters[j-1]
+		v := &s.directions[j]
+		for k := range sobolBits {
+			if k < p.s {
+				v[k] = p.m[k] << (sobolBits - 1 - k)
+				continue
+			}
+			v[k] = v[k-p.s] ^ (v[k-p.s] >> p.s)
+			for i := 1; i < p.s; i++ {
+				if (p.a>>(p.s-1-i))&1 == 1 {
+					v[k] ^= v[k-i]
+				}
+			}
+		}
+	}
+	return s
+}
+
+// point возвращает точку с номером index в порядке кода Грея
+func (s *sobol) point(index, dimensions int) []float64 {
+	gray := uint32(index ^ (index >> 1))
+	point := make([]float64, dimensions)
+	for j := range point {
+		var x uint32
+		for k := 0; gray>>k != 0; k++ {
+			if (gray>>k)&1 == 1 {
+				x ^= s.directions[j][k]
+			}
+		}
+		point[j] = float64(x) / (1 << sobolBits)
+	}
+	return point
+}
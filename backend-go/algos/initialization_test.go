@@ -0,0 +1,109 @@
+package algos
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestHalton(t *testing.T) {
+	tests := []struct {
+		index int
+		want  []float64
+	}{
+		{0, []float64{0, 0, 0}},
+		{1, []float64{1.0 / 2, 1.0 / 3, 1.0 / 5}},
+		{2, []float64{1.0 / 4, 2.0 / 3, 2.0 / 5}},
+		{3, []float64{3.0 / 4, 1.0 / 9, 3.0 / 5}},
+		{7, []float64{7.0 / 8, 5.0 / 9, 2.0/5 + 1.0/25}},
+	}
+	for _, test := range tests {
+		point := halton(test.index, len(test.want))
+		for j := range point {
+			if math.Abs(point[j]-test.want[j]) > 1e-12 {
+				t.Errorf("halton(%d) = %v, want %v", test.index, point, test.want)
+				break
+			}
+		}
+	}
+	if got := primes(6); !slices.Equal(got, []int{2, 3, 5, 7, 11, 13}) {
+		t.Errorf("primes(6) = %v", got)
+	}
+}
+
+func TestSobol(t *testing.T) {
+	s := newSobol(3)
+	tests := []struct {
+		index int
+		want  []float64
+	}{
+		{0, []float64{0, 0, 0}},
+		{1, []float64{0.5, 0.5, 0.5}},
+		{2, []float64{0.75, 0.25, 0.25}},
+		{3, []float64{0.25, 0.75, 0.75}},
+	}
+	for _, test := range tests {
+		if point := s.point(test.index, 3); !slices.Equal(point, test.want) {
+			t.Errorf("point(%d) = %v, want %v", test.index, point, test.want)
+		}
+	}
+
+	// в каждом измерении первые 2^k точек попадают по одной
+	// в каждый из 2^k равных отрезков
+	dimensions := len(sobolParameters) + 1
+	s = newSobol(dimensions)
+	const count = 1 << 8
+	hits := make([][]int, dimensions)
+	for j := range hits {
+		hits[j] = make([]int, count)
+	}
+	for index := range count {
+		point := s.point(index, dimensions)
+		for j, x := range point {
+			if x < 0 || x >= 1 {
+				t.Fatalf("point(%d) = %v вне [0, 1)", index, point)
+			}
+			hits[j][int(x*count)]++
+		}
+	}
+	for j := range hits {
+		if i := slices.IndexFunc(hits[j], func(n int) bool { return n != 1 }); i >= 0 {
+			t.Errorf("измерение %d: в отрезок %d попало %d точек", j+1, i, hits[j][i])
+		}
+	}
+}
+
+func TestFittest(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	tests := []struct {
+		name   string
+		values []float64
+		size   int
+		// номера отобранных позиций
+		want []int
+	}{
+		{"по возрастанию", []float64{3, 1, 2}, 2, []int{1, 2}},
+		{"все", []float64{3, 1, 2}, 3, []int{1, 2, 0}},
+		{"равные значения сохраняют порядок", []float64{2, 1, 2, 1}, 3, []int{1, 3, 0}},
+		{"NaN в конце", []float64{nan, 5, nan, 4}, 3, []int{3, 1, 0}},
+		{"бесконечность раньше NaN", []float64{nan, inf, 1}, 2, []int{2, 1}},
+		{"отрицательные", []float64{0, -inf, -1}, 2, []int{1, 2}},
+	}
+	for _, test := range tests {
+		positions := make([][]float64, len(test.values))
+		for i := range positions {
+			positions[i] = []float64{float64(i)}
+		}
+		selected, values := fittest(positions, test.values, test.size)
+		got := make([]int, len(selected))
+		for i, position := range selected {
+			got[i] = int(position[0])
+			if v := test.values[got[i]]; v != values[i] && !(math.IsNaN(v) && math.IsNaN(values[i])) {
+				t.Errorf("%s: значение %v у позиции %d, want %v", test.name, values[i], got[i], v)
+			}
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: отобраны %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+type LandscapeRequest struct {
+	// та же задача, что и в запросе алгоритма: функция, границы, преобразование и т. д.
+	AlgoRequest
+
+	// число точек сетки по каждой оси
+	Resolution *int `json:"resolution,omitempty"`
+	// номера двух координат (с 0), по которым строится срез; по умолчанию [0, 1]
+	Axes []int `json:"axes,omitempty"`
+	// значения остальных координат; по умолчанию — центр области поиска
+	Point []float64 `json:"point,omitempty"`
+	// область среза; по умолчанию — границы поиска по выбранным осям
+	Range [][]float64 `json:"range,omitempty"`
+}
+
+// Landscape — значения целевой функции на сетке: Values[i][j] вычислено
+// в точке (X[j], Y[i]). Для одномерной задачи Y пуст, а Values — одна строка.
+type Landscape struct {
+	Axes []int     `json:"axes"`
+	X    []float64 `json:"x"`
+	Y    []float64 `json:"y,omitempty"`
+	// неопределённые значения (NaN, ±Inf) передаются как null
+	Values [][]*float64 `json:"values"`
+	Min    *float64     `json:"min,omitempty"`
+	Max    *float64     `json:"max,omitempty"`
+	// допустимость точек сетки, если заданы ограничения
+	Feasible [][]bool `json:"feasible,omitempty"`
+}
+
+const maxLandscapeResolution = 500
+
+// NewLandscape вычисляет срез целевой функции той же задачи, что строит
+// NewAlgo, поэтому на графике — ровно то, что оптимизирует алгоритм: вместе
+// со штрафами за нарушение ограничений (как на первой итерации) и за выход
+// за границы. Шум не добавляется, бюджет вычислений не расходуется.
+func NewLandscape(request LandscapeRequest) (*Landscape, error) {
+	resolution := setDefault(request.Resolution, 50)
+	if resolution < 2 || resolution > maxLandscapeResolution {
+		return nil, fmt.Errorf("число точек сетки должно быть от 2 до %d", maxLandscapeResolution)
+	}
+
+	problem := request.AlgoRequest
+	problem.Population = nil
+	if problem.Bounds == nil && problem.NumDimensions == nil {
+		var dimensions int
+		switch {
+		case len(request.Population) > 0:
+			dimensions = len(request.Population[0])
+		case request.Point != nil:
+			dimensions = len(request.Point)
+		}
+		if dimensions > 0 {
+			problem.NumDimensions = &dimensions
+		}
+	}
+
+	algo, err := newProblem(problem)
+	if err != nil {
+		return nil, err
+	}
+	defer algo.Close()
+
+	if algo.Objectives != nil {
+		return nil, errors.New("поверхность строится только для однокритериальных задач")
+	}
+
+	axes := request.Axes
+	if axes == nil {
+		axes = []int{0, 1}
+		if algo.NumDimensions == 1 {
+			axes = []int{0}
+		}
+	}
+	if len(axes) < 1 || len(axes) > 2 || (len(axes) == 2 && axes[0] == axes[1]) {
+		return nil, errors.New("срез строится по одной или двум различным координатам")
+	}
+	for _, axis := range axes {
+		if axis < 0 || axis >= algo.NumDimensions {
+			return nil, fmt.Errorf("координата %d вне размерности задачи %d", axis, algo.NumDimensions)
+		}
+	}
+
+	point := request.Point
+	if point == nil {
+		point = make([]float64, algo.NumDimensions)
+		for j, bound := range algo.Bounds {
+			point[j] = (bound[0] + bound[1]) / 2
+		}
+	}
+	if len(point) != algo.NumDimensions {
+		return nil, errors.New("несоответствие размерности точки среза и размерности задачи")
+	}
+
+	ranges := request.Range
+	if ranges == nil {
+		for _, axis := range axes {
+			ranges = append(ranges, algo.Bounds[axis])
+		}
+	}
+	if len(ranges) != len(axes) {
+		return nil, errors.New("область среза должна быть задана для каждой оси")
+	}
+	for _, bound := range ranges {
+		if len(bound) != 2 || !(bound[0] < bound[1]) {
+			return nil, errors.New("неверно задана область среза")
+		}
+	}
+
+	landscape := &Landscape{Axes: axes, X: linspace(ranges[0], resolution)}
+	rows := 1
+	if len(axes) == 2 {
+		landscape.Y = linspace(ranges[1], resolution)
+		rows = resolution
+	}
+
+	positions := make([][]float64, 0, rows*resolution)
+	for i := range rows {
+		for _, x := range landscape.X {
+			position := slices.Clone(point)
+			position[axes[0]] = x
+			if landscape.Y != nil {
+				position[axes[1]] = landscape.Y[i]
+			}
+			positions = append(positions, position)
+		}
+	}
+
+	// дискретные координаты алгоритм видит только в допустимых значениях
+	if algo.Variables != nil {
+		for _, position := range positions {
+			algo.repair(position)
+		}
+	}
+
+	// значения в знаке минимизации, как их сравнивает алгоритм
+	var values []float64
+	if algo.Batch != nil {
+		values = algo.evaluateBatch(positions)
+		for i := range values {
+			if algo.Maximize && !math.IsInf(values[i], 1) {
+				values[i] = -values[i]
+			}
+		}
+	} else {
+		values = evaluateParallel(algo.exactObjective, positions)
+	}
+
+	var violations []float64
+	if c := algo.Constraints; c != nil {
+		violations = make([]float64, len(positions))
+		for k, position := range positions {
+			violations[k] = c.Violation(position)
+		}
+		// штраф — как на первой итерации; сетка заменяет начальную популяцию при выборе ε
+		c.initEpsilon(positions)
+		raw := values
+		values = make([]float64, len(raw))
+		for range 2 {
+			// правила Деба сравнивают недопустимые точки с худшим допустимым значением,
+			// поэтому оно сначала находится по всей сетке, а затем значения пересчитываются
+			for k := range values {
+				values[k] = c.Fitness(raw[k], violations[k])
+			}
+		}
+	}
+	for k, position := range positions {
+		values[k] = algo.userValue(values[k] + algo.boundaryPenalty(position))
+	}
+
+	landscape.Values = make([][]*float64, rows)
+	if algo.Constraints != nil {
+		landscape.Feasible = make([][]bool, rows)
+	}
+	for i := range rows {
+		landscape.Values[i] = make([]*float64, resolution)
+		if landscape.Feasible != nil {
+			landscape.Feasible[i] = make([]bool, resolution)
+		}
+		for j := range resolution {
+			k := i*resolution + j
+			if landscape.Feasible != nil {
+				landscape.Feasible[i][j] = violations[k] == 0
+			}
+
+			value := values[k]
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			landscape.Values[i][j] = &value
+			if landscape.Min == nil || value < *landscape.Min {
+				landscape.Min = &value
+			}
+			if landscape.Max == nil || value > *landscape.Max {
+				landscape.Max = &value
+			}
+		}
+	}
+	return landscape, nil
+}
+
+func linspace(bound []float64, count int) []float64 {
+	points := make([]float64, count)
+	step := (bound[1] - bound[0]) / float64(count-1)
+	for i := range points {
+		points[i] = bound[0] + float64(i)*step
+	}
+	points[count-1] = bound[1]
+	return points
+}
@@ -0,0 +1,61 @@
+package algos
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// на сетке — приспособленность, которую сравнивает алгоритм: со штрафами
+// за нарушение ограничений и за выход за границы, без шума и без расхода бюджета
+func TestLandscapePenalties(t *testing.T) {
+	dimensions, resolution, budget := 1, 5, 1
+	penalty := 10.0
+	bounds := [][]float64{{-2, 2}}
+	constraints := func(method string) *ConstraintsRequest {
+		return &ConstraintsRequest{Inequalities: []string{"x - 0.5"}, Method: method}
+	}
+	tests := []struct {
+		name    string
+		request AlgoRequest
+		// область среза; nil — границы поиска
+		ranges [][]float64
+		want   []float64
+	}{
+		{"без штрафов", AlgoRequest{Func: "x^2"}, nil, []float64{4, 1, 0, 1, 4}},
+		{"статический штраф", AlgoRequest{Func: "x^2", Constraints: constraints(StaticPenalty)}, nil, []float64{4, 1, 0, 1 + 1e6*0.5, 4 + 1e6*1.5}},
+		// худшее допустимое значение на сетке — 4
+		{"правила Деба", AlgoRequest{Func: "x^2", Constraints: constraints(FeasibilityRule)}, nil, []float64{4, 1, 0, 4.5, 5.5}},
+		{"штраф за выход за границы", AlgoRequest{Func: "x^2", Boundary: &BoundaryRequest{Method: PenaltyBoundary, Penalty: &penalty}}, [][]float64{{-2, 6}}, []float64{4, 0, 4, 16 + 20, 36 + 40}},
+		{"максимизация со штрафом", AlgoRequest{Func: "-x^2", Direction: "max", Constraints: constraints(StaticPenalty)}, nil, []float64{-4, -1, 0, -1 - 1e6*0.5, -4 - 1e6*1.5}},
+		{"шум не добавляется", AlgoRequest{Func: "x^2", Noise: &NoiseRequest{Level: 1}}, nil, []float64{4, 1, 0, 1, 4}},
+	}
+	for _, test := range tests {
+		request := test.request
+		request.Iterations = 1
+		request.NumDimensions = &dimensions
+		request.Bounds = bounds
+		request.MaxEvaluations = &budget
+		landscape, err := NewLandscape(LandscapeRequest{AlgoRequest: request, Resolution: &resolution, Range: test.ranges})
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got := make([]float64, len(landscape.Values[0]))
+		for j, value := range landscape.Values[0] {
+			got[j] = math.NaN()
+			if value != nil {
+				got[j] = *value
+			}
+		}
+		for j := range got {
+			if math.Abs(got[j]-test.want[j]) > 1e-9 {
+				t.Errorf("%s: значения %v, want %v", test.name, got, test.want)
+				break
+			}
+		}
+		if request.Constraints != nil && !slices.Equal(landscape.Feasible[0], []bool{true, true, true, false, false}) {
+			t.Errorf("%s: допустимость %v", test.name, landscape.Feasible[0])
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+type LocalSearchRequest struct {
+	// lbfgs или gradient
+	Method string `json:"method,omitempty"`
+	// уточнять лучшее решение каждые every итераций; 0 — только в конце
+	Every *int `json:"every,omitempty"`
+	// максимальное число шагов локального поиска за один запуск
+	MaxIterations *int `json:"maxIterations,omitempty"`
+	// поиск останавливается, когда норма проекции градиента меньше tolerance
+	Tolerance *float64 `json:"tolerance,omitempty"`
+	// число запоминаемых пар шагов L-BFGS
+	History *int `json:"history,omitempty"`
+}
+
+// методы локального поиска
+const (
+	LBFGSSearch    = "lbfgs"
+	GradientSearch = "gradient"
+)
+
+// LocalSearch уточняет лучшее решение градиентным методом с учётом границ
+// поиска. Градиент выражения вычисляется автоматическим дифференцированием,
+// остальных целевых функций — центральными разностями.
+type LocalSearch struct {
+	Method        string
+	Every         int
+	MaxIterations int
+	Tolerance     float64
+	History       int
+
+	// точный градиент Func, если он доступен
+	gradient func([]float64) (float64, []float64, error)
+}
+
+func NewLocalSearch(request LocalSearchRequest) (*LocalSearch, error) {
+	search := &LocalSearch{
+		Method:        request.Method,
+		Every:         setDefault(request.Every, 0),
+		MaxIterations: setDefault(request.MaxIterations, 50),
+		Tolerance:     setDefault(request.Tolerance, 1e-8),
+		History:       setDefault(request.History, 10),
+	}
+	if search.Method == "" {
+		search.Method = LBFGSSearch
+	}
+	if search.Method != LBFGSSearch && search.Method != GradientSearch {
+		return nil, fmt.Errorf("неизвестный метод локального поиска %q", search.Method)
+	}
+	if search.Every < 0 {
+		return nil, errors.New("период локального поиска не может быть отрицательным")
+	}
+	if search.MaxIterations < 1 {
+		return nil, errors.New("число шагов локального поиска должно быть больше 0")
+	}
+	if search.History < 1 {
+		return nil, errors.New("размер истории L-BFGS должен быть больше 0")
+	}
+	return search, nil
+}
+
+// refine запускает локальный поиск из лучшего решения и при улучшении
+// переносит в найденную точку и лучшее решение, и лучшего агента
+func (algo *Algo) refine() {
+	// сработавший критерий остановки не мешает уточнить решение в конце работы
+	if algo.LocalSearch == nil || algo.GlobalBestPosition == nil || algo.Clock.Exhausted() {
+		return
+	}
+
+	position, value := algo.minimizeLocally(slices.Clone(algo.GlobalBestPosition))
+	if value >= algo.GlobalBestValue {
+		return
+	}
+	best := 0
+	for i, fitness := range algo.Fitness {
+		if fitness < algo.Fitness[best] {
+			best = i
+		}
+	}
+	algo.moveAgent(best, slices.Clone(position), value)
+	algo.setBest(position, value, -1)
+}
+
+// gradientAt возвращает значение Func и её градиент; nil означает,
+// что бюджет вычислений исчерпан
+func (algo *Algo) gradientAt(x []float64) (float64, []float64) {
+	if algo.LocalSearch.gradient != nil {
+		if algo.Clock.reserve(1) == 0 {
+			return math.Inf(1), nil
+		}
+		value, gradient, err := algo.LocalSearch.gradient(x)
+		if err == nil {
+			return value, gradient
+		}
+	}
+
+	value := algo.Func(x)
+	gradient := make([]float64, len(x))
+	for j := range x {
+		if !algo.continuous(j) {
+			continue
+		}
+		// шаг разностной схемы не выводит точку за границы поиска
+		h := 1e-6 * math.Max(1, math.Abs(x[j]))
+		forward, backward := slices.Clone(x), slices.Clone(x)
+		forward[j] = math.Min(x[j]+h, algo.Bounds[j][1])
+		backward[j] = math.Max(x[j]-h, algo.Bounds[j][0])
+		if forward[j] == backward[j] {
+			continue
+		}
+		gradient[j] = (algo.Func(forward) - algo.Func(backward)) / (forward[j] - backward[j])
+	}
+	if algo.Clock.Exhausted() {
+		return value, nil
+	}
+	return value, gradient
+}
+
+// minimizeLocally — проекционный L-BFGS (или градиентный спуск)
+// с поиском шага по правилу Армихо
+func (algo *Algo) minimizeLocally(x []float64) ([]float64, float64) {
+	search := algo.LocalSearch
+	value, gradient := algo.gradientAt(x)
+	if gradient == nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return x, math.Inf(1)
+	}
+
+	var steps, changes [][]float64
+	for range search.MaxIterations {
+		projected := algo.projectGradient(x, gradient)
+		if norm(projected) < search.Tolerance {
+			break
+		}
+
+		direction := scaled(projected, -1)
+		if search.Method == LBFGSSearch && len(steps) > 0 {
+			direction = lbfgsDirection(projected, steps, changes)
+			if dot(direction, projected) >= 0 {
+				// направление не ведёт к спуску — начинаем историю заново
+				steps, changes = nil, nil
+				direction = scaled(projected, -1)
+			}
+		}
+		if search.Method == GradientSearch || len(steps) == 0 {
+			// первый шаг порядка единицы по максимальной координате
+			direction = scaled(direction, 1/math.Max(1, maxAbs(direction)))
+		}
+
+		next, nextValue, ok := algo.lineSearch(x, value, gradient, direction)
+		if !ok {
+			break
+		}
+		nextValue, nextGradient := algo.gradientAt(next)
+		if nextGradient == nil {
+			return next, nextValue
+		}
+
+		step, change := subtract(next, x), subtract(nextGradient, gradient)
+		if dot(step, change) > 1e-12 {
+			steps, changes = append(steps, step), append(changes, change)
+			if len(steps) > search.History {
+				steps, changes = steps[1:], changes[1:]
+			}
+		}
+		x, value, gradient = next, nextValue, nextGradient
+	}
+	return x, value
+}
+
+// lineSearch уменьшает шаг вдоль направления, пока значение не убывает достаточно
+func (algo *Algo) lineSearch(x []float64, value float64, gradient, direction []float64) ([]float64, float64, bool) {
+	const armijo = 1e-4
+	alpha := 1.0
+	for range 40 {
+		next := make([]float64, len(x))
+		for j := range x {
+			next[j] = x[j] + alpha*direction[j]
+		}
+		algo.repair(next)
+		step := subtract(next, x)
+		if norm(step) == 0 {
+			return nil, 0, false
+		}
+		nextValue := algo.Func(next)
+		if algo.Clock.Exhausted() && math.IsInf(nextValue, 1) {
+			return nil, 0, false
+		}
+		if nextValue <= value+armijo*dot(gradient, step) {
+			return next, nextValue, true
+		}
+		alpha /= 2
+	}
+	return nil, 0, false
+}
+
+// projectGradient обнуляет составляющие градиента, выводящие за активные границы,
+// и по дискретным координатам: локальный поиск их не меняет
+func (algo *Algo) projectGradient(x, gradient []float64) []float64 {
+	projected := slices.Clone(gradient)
+	for j := range projected {
+		if !algo.continuous(j) || (x[j] <= algo.Bounds[j][0] && projected[j] > 0) || (x[j] >= algo.Bounds[j][1] && projected[j] < 0) {
+			projected[j] = 0
+		}
+	}
+	return projected
+}
+
+// lbfgsDirection — двухпетлевая рекурсия L-BFGS: −H·g по истории шагов
+func lbfgsDirection(gradient []float64, steps, changes [][]float64) []float64 {
+	q := slices.Clone(gradient)
+	alphas := make([]float64, len(steps))
+	for i := len(steps) - 1; i >= 0; i-- {
+		rho := 1 / dot(changes[i], steps[i])
+		alphas[i] = rho * dot(steps[i], q)
+		for j := range q {
+			q[j] -= alphas[i] * changes[i][j]
+		}
+	}
+
+	last := len(steps) - 1
+	gamma := dot(steps[last], changes[last]) / dot(changes[last], changes[last])
+	for j := range q {
+		q[j] *= gamma
+	}
+
+	for i := range steps {
+		rho := 1 / dot(changes[i], steps[i])
+		beta := rho * dot(changes[i], q)
+		for j := range q {
+			q[j] += steps[i][j] * (alphas[i] - beta)
+		}
+	}
+	return scaled(q, -1)
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func norm(a []float64) float64 {
+	return math.Sqrt(dot(a, a))
+}
+
+func maxAbs(a []float64) float64 {
+	result := 0.0
+	for _, v := range a {
+		result = math.Max(result, math.Abs(v))
+	}
+	return result
+}
+
+func scaled(a []float64, factor float64) []float64 {
+	result := make([]float64, len(a))
+	for i := range a {
+		result[i] = a[i] * factor
+	}
+	return result
+}
+
+func subtract(a, b []float64) []float64 {
+	result := make([]float64, len(a))
+	for i := range a {
+		result[i] = a[i] - b[i]
+	}
+	return result
+}
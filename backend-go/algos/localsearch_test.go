@@ -0,0 +1,59 @@
+package algos
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLbfgsDirection(t *testing.T) {
+	// квадратичная функция с матрицей A: изменения градиента y = A·s
+	multiply := func(a [][]float64, s []float64) []float64 {
+		y := make([]float64, len(s))
+		for i, row := range a {
+			y[i] = dot(row, s)
+		}
+		return y
+	}
+	tests := []struct {
+		name     string
+		a        [][]float64
+		steps    [][]float64
+		gradient []float64
+		// направление; nil — проверяется только условие секущей и спуск
+		want []float64
+	}{
+		{"A = 4I: шаг Ньютона", [][]float64{{4, 0}, {0, 4}}, [][]float64{{1, -2}}, []float64{8, 4}, []float64{-2, -1}},
+		{"одна координата", [][]float64{{2}}, [][]float64{{0.5}}, []float64{3}, []float64{-1.5}},
+		{"диагональная матрица, два шага", [][]float64{{1, 0}, {0, 10}}, [][]float64{{1, 0}, {0, 1}}, []float64{1, 10}, []float64{-1, -1}},
+		{"недиагональная матрица", [][]float64{{3, 1, 0}, {1, 2, 0.5}, {0, 0.5, 1}}, [][]float64{{1, 0, 0}, {0.3, 1, 0}, {0, -0.2, 1}}, []float64{1, -1, 2}, nil},
+	}
+	for _, test := range tests {
+		changes := make([][]float64, len(test.steps))
+		for i, s := range test.steps {
+			changes[i] = multiply(test.a, s)
+		}
+
+		direction := lbfgsDirection(test.gradient, test.steps, changes)
+		if test.want != nil {
+			for j := range direction {
+				if math.Abs(direction[j]-test.want[j]) > 1e-12 {
+					t.Errorf("%s: направление %v, want %v", test.name, direction, test.want)
+					break
+				}
+			}
+		}
+		if dot(direction, test.gradient) >= 0 {
+			t.Errorf("%s: направление %v не убывания при градиенте %v", test.name, direction, test.gradient)
+		}
+
+		// условие секущей для последней пары: H·y = s
+		last := len(test.steps) - 1
+		secant := lbfgsDirection(changes[last], test.steps, changes)
+		for j := range secant {
+			if math.Abs(secant[j]+test.steps[last][j]) > 1e-12 {
+				t.Errorf("%s: H·y = %v, want %v", test.name, scaled(secant, -1), test.steps[last])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package algos
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sync"
+)
+
+type NoiseRequest struct {
+	Distribution string   `json:"distribution,omitempty"`
+	Level        float64  `json:"level"`
+	Resampling   string   `json:"resampling,omitempty"`
+	Samples      *int     `json:"samples,omitempty"`
+	MaxSamples   *int     `json:"maxSamples,omitempty"`
+	Tolerance    *float64 `json:"tolerance,omitempty"`
+}
+
+// распределения шума
+const (
+	GaussianNoise = "gaussian" // N(0, level²)
+	UniformNoise  = "uniform"  // U(-level, level)
+)
+
+// стратегии повторных вычислений
+const (
+	NoResampling       = "none"
+	FixedResampling    = "fixed"    // среднее по samples вычислениям
+	AdaptiveResampling = "adaptive" // вычисления до стандартной ошибки не больше tolerance
+	EliteResampling    = "elite"    // лучшее решение перевычисляется на каждой итерации
+)
+
+// Noise добавляет к целевой функции случайный шум и сглаживает его
+// повторными вычислениями.
+type Noise struct {
+	Distribution string
+	Level        float64
+	Resampling   string
+	Samples      int
+	MaxSamples   int
+	Tolerance    float64
+
+	mu     sync.Mutex
+	rng    *rand.Rand
+	source *generator
+
+	// накопленные вычисления текущего лучшего решения
+	elite      []float64
+	eliteSum   float64
+	eliteCount int
+}
+
+func NewNoise(request NoiseRequest, seed int64) (*Noise, error) {
+	noise := &Noise{
+		Distribution: request.Distribution,
+		Level:        request.Level,
+		Resampling:   request.Resampling,
+		Samples:      setDefault(request.Samples, 5),
+		MaxSamples:   setDefault(request.MaxSamples, 30),
+		Tolerance:    setDefault(request.Tolerance, request.Level/3),
+	}
+
+	switch noise.Distribution {
+	case "":
+		noise.Distribution = GaussianNoise
+	case GaussianNoise, UniformNoise:
+	default:
+		return nil, fmt.Errorf("неизвестное распределение шума %q", request.Distribution)
+	}
+
+	switch noise.Resampling {
+	case "":
+		noise.Resampling = NoResampling
+	case NoResampling, FixedResampling, AdaptiveResampling, EliteResampling:
+	default:
+		return nil, fmt.Errorf("неизвестная стратегия повторных вычислений %q", request.Resampling)
+	}
+
+	if noise.Level < 0 {
+		return nil, fmt.Errorf("уровень шума должен быть неотрицательным")
+	}
+	if noise.Samples < 1 || noise.MaxSamples < noise.Samples {
+		return nil, fmt.Errorf("неверно заданы числа повторных вычислений")
+	}
+
+	noise.source = newGenerator(seed)
+	noise.rng = rand.New(noise.source)
+
+	return noise, nil
+}
+
+func (n *Noise) draw() float64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.Distribution == UniformNoise {
+		return (n.rng.Float64()*2 - 1) * n.Level
+	}
+	return n.rng.NormFloat64() * n.Level
+}
+
+func (n *Noise) wrap(function func([]float64) float64) func([]float64) float64 {
+	noisy := func(position []float64) float64 {
+		return function(position) + n.draw()
+	}
+
+	switch n.Resampling {
+	case FixedResampling:
+		return func(position []float64) float64 {
+			sum := 0.0
+			for range n.Samples {
+				sum += noisy(position)
+			}
+			return sum / float64(n.Samples)
+		}
+
+	case AdaptiveResampling:
+		return func(position []float64) float64 {
+			sum, squares := 0.0, 0.0
+			count := 0
+			for count < n.MaxSamples {
+				value := noisy(position)
+				sum += value
+				squares += value * value
+				count++
+
+				if count >= max(n.Samples, 2) {
+					mean := sum / float64(count)
+					variance := math.Max(0, squares/float64(count)-mean*mean)
+					if math.Sqrt(variance/float64(count)) <= n.Tolerance {
+						break
+					}
+				}
+			}
+			return sum / float64(count)
+		}
+	}
+
+	return noisy
+}
+
+// reevaluate добавляет samples вычислений лучшего решения к уже накопленным
+// и возвращает их среднее; при смене лучшего решения накопление начинается заново
+func (n *Noise) reevaluate(function func([]float64) float64, position []float64, value float64) float64 {
+	if !slices.Equal(n.elite, position) {
+		n.elite = slices.Clone(position)
+		n.eliteSum, n.eliteCount = value, 1
+	}
+	for range n.Samples {
+		n.eliteSum += function(position)
+		n.eliteCount++
+	}
+	return n.eliteSum / float64(n.eliteCount)
+}
@@ -0,0 +1,144 @@
+package algos
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestNoiseRequestErrors(t *testing.T) {
+	zero, three := 0, 3
+	for _, request := range []NoiseRequest{
+		{Distribution: "cauchy", Level: 1},
+		{Level: 1, Resampling: "always"},
+		{Level: -1},
+		{Level: 1, Samples: &zero},
+		{Level: 1, Samples: &three, MaxSamples: &zero},
+	} {
+		if _, err := NewNoise(request, 1); err == nil {
+			t.Errorf("%+v: ожидалась ошибка", request)
+		}
+	}
+}
+
+// повторные вычисления усредняют шум тех же значений, что даёт генератор с тем же зерном
+func TestNoiseResampling(t *testing.T) {
+	one, samples, maxSamples := 1, 3, 20
+	tolerance := 0.0
+	tests := []struct {
+		name    string
+		request NoiseRequest
+		// ожидаемое число вычислений функции
+		calls int
+	}{
+		{"без повторов", NoiseRequest{Level: 1}, 1},
+		{"равномерный шум", NoiseRequest{Distribution: UniformNoise, Level: 1}, 1},
+		{"фиксированное число повторов", NoiseRequest{Level: 1, Resampling: FixedResampling, Samples: &samples}, samples},
+		{"адаптивные повторы до наибольшего числа", NoiseRequest{Level: 1, Resampling: AdaptiveResampling, Samples: &samples, MaxSamples: &maxSamples, Tolerance: &tolerance}, maxSamples},
+		// без шума стандартная ошибка нулевая уже после наименьшего числа вычислений
+		{"адаптивные повторы без шума", NoiseRequest{Level: 0, Resampling: AdaptiveResampling, Samples: &samples, MaxSamples: &maxSamples}, samples},
+		// дисперсия оценивается хотя бы по двум вычислениям
+		{"адаптивные повторы с одним вычислением", NoiseRequest{Level: 0, Resampling: AdaptiveResampling, Samples: &one, MaxSamples: &maxSamples}, 2},
+	}
+	for _, test := range tests {
+		seed := int64(7)
+		noise, err := NewNoise(test.request, seed)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		calls := 0
+		function := noise.wrap(func([]float64) float64 {
+			calls++
+			return 1
+		})
+		got := function([]float64{0})
+
+		reference := rand.New(newGenerator(seed))
+		want := 0.0
+		for range test.calls {
+			if noise.Distribution == UniformNoise {
+				want += 1 + (reference.Float64()*2-1)*noise.Level
+			} else {
+				want += 1 + reference.NormFloat64()*noise.Level
+			}
+		}
+		want /= float64(test.calls)
+		if calls != test.calls || math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s: %d вычислений, значение %v; want %d, %v", test.name, calls, got, test.calls, want)
+		}
+	}
+}
+
+func TestNoiseReevaluate(t *testing.T) {
+	samples := 2
+	noise, err := NewNoise(NoiseRequest{Level: 1, Resampling: EliteResampling, Samples: &samples}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// функция возвращает номер вычисления
+	calls := 0.0
+	function := func([]float64) float64 {
+		calls++
+		return calls
+	}
+	tests := []struct {
+		position []float64
+		value    float64
+		want     float64
+	}{
+		{[]float64{1, 2}, 10, (10 + 1 + 2) / 3.0},
+		// то же решение: вычисления накапливаются, переданное значение не учитывается
+		{[]float64{1, 2}, -100, (10 + 1 + 2 + 3 + 4) / 5.0},
+		// новое решение: накопление начинается заново
+		{[]float64{2, 1}, 20, (20 + 5 + 6) / 3.0},
+	}
+	for i, test := range tests {
+		if got := noise.reevaluate(function, test.position, test.value); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("перевычисление %d: %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+// при перевычислении лучшего решения его значение — среднее всех его вычислений,
+// а повторные вычисления расходуют бюджет
+func TestEliteResampling(t *testing.T) {
+	size, seed, dimensions, samples := 10, 3, 2, 4
+	iterations := 30
+	gwo, err := newGWO(GWORequest{AlgoRequest: AlgoRequest{
+		Func: "x^2 + y^2", Iterations: iterations, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+		Noise: &NoiseRequest{Level: 0.5, Resampling: EliteResampling, Samples: &samples},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gwo.Close()
+	gwo.Init(context.Background())
+
+	accumulated := 0
+	for step := 1; ; step++ {
+		response, ok := gwo.Step()
+		if !ok {
+			break
+		}
+		// популяция и каждая итерация стаи — по вычислению на волка, плюс повторы лучшего
+		if want := size*(step+1) + samples*step; response.Evaluations != want {
+			t.Fatalf("итерация %d: %d вычислений, want %d", step, response.Evaluations, want)
+		}
+		noise := gwo.Noise
+		if !slices.Equal(gwo.GlobalBestPosition, noise.elite) {
+			continue
+		}
+		if mean := noise.eliteSum / float64(noise.eliteCount); gwo.GlobalBestValue != mean {
+			t.Errorf("итерация %d: лучшее значение %v, среднее %d вычислений %v", step, gwo.GlobalBestValue, noise.eliteCount, mean)
+		}
+		if noise.eliteCount > samples+1 {
+			accumulated++
+		}
+	}
+	if accumulated == 0 {
+		t.Error("вычисления лучшего решения ни разу не накапливались между итерациями")
+	}
+}
@@ -0,0 +1,235 @@
+package algos
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+)
+
+type ParetoFront struct {
+	Positions [][]float64 `json:"positions"`
+	Values    [][]float64 `json:"values"`
+}
+
+// ParetoArchive хранит недоминируемые решения; при переполнении
+// удаляются решения из самых плотных участков фронта (crowding distance).
+type ParetoArchive struct {
+	ParetoFront
+	Capacity int
+}
+
+func NewParetoArchive(capacity int) *ParetoArchive {
+	return &ParetoArchive{Capacity: capacity}
+}
+
+// dominates проверяет, что a не хуже b по всем критериям и строго лучше хотя бы по одному
+func dominates(a, b []float64) bool {
+	better := false
+	for i := range a {
+		if a[i] > b[i] {
+			return false
+		}
+		if a[i] < b[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// Add добавляет решение в архив, если его не доминирует ни одно из хранящихся
+func (archive *ParetoArchive) Add(position, values []float64) bool {
+	kept := 0
+	for i, member := range archive.Values {
+		if dominates(member, values) || slices.Equal(member, values) {
+			return false
+		}
+		if !dominates(values, member) {
+			archive.Positions[kept] = archive.Positions[i]
+			archive.Values[kept] = member
+			kept++
+		}
+	}
+	archive.Positions = append(archive.Positions[:kept], slices.Clone(position))
+	archive.Values = append(archive.Values[:kept], slices.Clone(values))
+
+	for len(archive.Values) > archive.Capacity {
+		archive.removeMostCrowded()
+	}
+	return true
+}
+
+func (archive *ParetoArchive) removeMostCrowded() {
+	distances := crowdingDistances(archive.Values)
+	worst := 0
+	for i, distance := range distances {
+		if distance < distances[worst] {
+			worst = i
+		}
+	}
+	archive.Positions = slices.Delete(archive.Positions, worst, worst+1)
+	archive.Values = slices.Delete(archive.Values, worst, worst+1)
+}
+
+// SelectLeader выбирает решение из архива бинарным турниром, предпочитая
+// менее плотные участки фронта, чтобы стая равномерно его покрывала
+func (archive *ParetoArchive) SelectLeader(rng *rand.Rand) []float64 {
+	if len(archive.Positions) == 0 {
+		return nil
+	}
+	distances := crowdingDistances(archive.Values)
+	i, j := rng.Intn(len(distances)), rng.Intn(len(distances))
+	if distances[j] > distances[i] {
+		i = j
+	}
+	return archive.Positions[i]
+}
+
+// Front возвращает копию текущего фронта
+func (archive *ParetoArchive) Front() *ParetoFront {
+	front := &ParetoFront{
+		Positions: make([][]float64, len(archive.Positions)),
+		Values:    make([][]float64, len(archive.Values)),
+	}
+	for i := range archive.Positions {
+		front.Positions[i] = slices.Clone(archive.Positions[i])
+		front.Values[i] = slices.Clone(archive.Values[i])
+	}
+	return front
+}
+
+func crowdingDistances(values [][]float64) []float64 {
+	distances := make([]float64, len(values))
+	if len(values) < 3 {
+		for i := range distances {
+			distances[i] = math.Inf(1)
+		}
+		return distances
+	}
+
+	order := make([]int, len(values))
+	for m := range values[0] {
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int {
+			return cmpFloat(values[a][m], values[b][m])
+		})
+
+		first, last := values[order[0]][m], values[order[len(order)-1]][m]
+		distances[order[0]] = math.Inf(1)
+		distances[order[len(order)-1]] = math.Inf(1)
+		if last == first {
+			continue
+		}
+		for k := 1; k < len(order)-1; k++ {
+			distances[order[k]] += (values[order[k+1]][m] - values[order[k-1]][m]) / (last - first)
+		}
+	}
+	return distances
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Hypervolume вычисляет объём области, доминируемой фронтом и ограниченной
+// опорной точкой (рекурсивным разбиением по последнему критерию)
+func Hypervolume(front [][]float64, reference []float64) float64 {
+	points := make([][]float64, 0, len(front))
+	for _, point := range front {
+		inside := true
+		for m := range point {
+			if point[m] >= reference[m] {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			points = append(points, point)
+		}
+	}
+	return hypervolume(points, reference)
+}
+
+func hypervolume(points [][]float64, reference []float64) float64 {
+	if len(points) == 0 {
+		return 0
+	}
+
+	m := len(reference) - 1
+	if m == 0 {
+		best := reference[0]
+		for _, point := range points {
+			best = math.Min(best, point[0])
+		}
+		return reference[0] - best
+	}
+
+	sorted := slices.Clone(points)
+	slices.SortFunc(sorted, func(a, b []float64) int {
+		return cmpFloat(a[m], b[m])
+	})
+
+	volume := 0.0
+	for i, point := range sorted {
+		upper := reference[m]
+		if i+1 < len(sorted) {
+			upper = sorted[i+1][m]
+		}
+		if upper <= point[m] {
+			continue
+		}
+
+		// срез из точек, не хуже текущей по последнему критерию
+		slice := make([][]float64, i+1)
+		for k := range slice {
+			slice[k] = sorted[k][:m]
+		}
+		volume += hypervolume(nonDominated(slice), reference[:m]) * (upper - point[m])
+	}
+	return volume
+}
+
+func nonDominated(points [][]float64) [][]float64 {
+	result := make([][]float64, 0, len(points))
+	for i, point := range points {
+		dominated := false
+		for j, other := range points {
+			if i != j && (dominates(other, point) || (j < i && slices.Equal(other, point))) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			result = append(result, point)
+		}
+	}
+	return result
+}
+
+// IGD — среднее расстояние от точек эталонного фронта до ближайшей точки найденного
+func IGD(front, reference [][]float64) float64 {
+	if len(front) == 0 {
+		return math.Inf(1)
+	}
+
+	sum := 0.0
+	for _, target := range reference {
+		nearest := math.Inf(1)
+		for _, point := range front {
+			distance := 0.0
+			for m := range point {
+				distance += (point[m] - target[m]) * (point[m] - target[m])
+			}
+			nearest = math.Min(nearest, distance)
+		}
+		sum += math.Sqrt(nearest)
+	}
+	return sum / float64(len(reference))
+}
@@ -0,0 +1,152 @@
+package algos
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDominates(t *testing.T) {
+	tests := []struct {
+		a, b []float64
+		want bool
+	}{
+		{[]float64{1, 1}, []float64{2, 2}, true},
+		{[]float64{1, 2}, []float64{1, 3}, true},
+		{[]float64{1, 1}, []float64{1, 1}, false},
+		{[]float64{1, 3}, []float64{2, 2}, false},
+		{[]float64{2, 2}, []float64{1, 1}, false},
+	}
+	for _, test := range tests {
+		if got := dominates(test.a, test.b); got != test.want {
+			t.Errorf("dominates(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestParetoArchiveAdd(t *testing.T) {
+	archive := NewParetoArchive(3)
+	steps := []struct {
+		values []float64
+		added  bool
+		size   int
+	}{
+		{[]float64{2, 2}, true, 1},
+		{[]float64{3, 3}, false, 1}, // доминируется
+		{[]float64{2, 2}, false, 1}, // уже есть
+		{[]float64{1, 3}, true, 2},
+		{[]float64{3, 1}, true, 3},
+		{[]float64{1, 1}, true, 1}, // доминирует все
+		{[]float64{0, 4}, true, 2},
+		{[]float64{4, 0}, true, 3},
+		{[]float64{0.5, 3}, true, 3}, // переполнение: удаляется самое плотное
+	}
+	for _, step := range steps {
+		if added := archive.Add(step.values, step.values); added != step.added {
+			t.Errorf("Add(%v) = %v, want %v", step.values, added, step.added)
+		}
+		if len(archive.Values) != step.size {
+			t.Fatalf("после Add(%v) в архиве %d решений, want %d", step.values, len(archive.Values), step.size)
+		}
+	}
+	for i, a := range archive.Values {
+		for j, b := range archive.Values {
+			if i != j && dominates(a, b) {
+				t.Errorf("в архиве %v доминирует %v", a, b)
+			}
+		}
+	}
+}
+
+func TestHypervolume(t *testing.T) {
+	tests := []struct {
+		name      string
+		front     [][]float64
+		reference []float64
+		want      float64
+	}{
+		{"лестница", [][]float64{{1, 3}, {2, 2}, {3, 1}}, []float64{4, 4}, 6},
+		{"доминируемая точка не меняет объём", [][]float64{{1, 3}, {2, 2}, {3, 1}, {3, 3}}, []float64{4, 4}, 6},
+		{"точка за опорной не учитывается", [][]float64{{1, 1}, {5, 0}}, []float64{2, 2}, 1},
+		{"пустой фронт", nil, []float64{1, 1}, 0},
+		{"одна точка в 3D", [][]float64{{0, 0, 0}}, []float64{1, 2, 3}, 6},
+		{"пересекающиеся параллелепипеды", [][]float64{{0, 0, 1}, {1, 1, 0}}, []float64{2, 2, 2}, 5},
+	}
+	for _, test := range tests {
+		if got := Hypervolume(test.front, test.reference); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s: Hypervolume = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIGD(t *testing.T) {
+	tests := []struct {
+		name             string
+		front, reference [][]float64
+		want             float64
+	}{
+		{"фронт совпадает с эталоном", [][]float64{{0, 1}, {1, 0}}, [][]float64{{0, 1}, {1, 0}}, 0},
+		{"среднее расстояние до ближайшей точки", [][]float64{{0, 0}}, [][]float64{{3, 4}, {0, 0}}, 2.5},
+		{"пустой фронт", nil, [][]float64{{0, 0}}, math.Inf(1)},
+	}
+	for _, test := range tests {
+		if got := IGD(test.front, test.reference); got != test.want {
+			t.Errorf("%s: IGD = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestObjectivesCount(t *testing.T) {
+	size, dimensions := 6, 2
+	single := AlgoRequest{Func: "x^2 + y^2", Iterations: 5, PopulationSize: &size, NumDimensions: &dimensions}
+	multi := AlgoRequest{Objectives: []string{"x^2 + y^2", "(x-2)^2 + y^2"}, Iterations: 5, PopulationSize: &size, NumDimensions: &dimensions}
+	benchmark := AlgoRequest{Benchmark: "zdt1", Iterations: 5, PopulationSize: &size}
+
+	if _, err := NewGWO(GWORequest{AlgoRequest: multi}); err == nil || !strings.Contains(err.Error(), "однокритериальный") {
+		t.Errorf("однокритериальный GWO принял две целевые функции: %v", err)
+	}
+	if _, err := NewABC(ABCRequest{AlgoRequest: benchmark}); err == nil || !strings.Contains(err.Error(), "однокритериальный") {
+		t.Errorf("однокритериальный ABC принял многокритериальную тестовую функцию: %v", err)
+	}
+	if _, err := NewMOGWO(GWORequest{AlgoRequest: single}); err == nil || !strings.Contains(err.Error(), "не меньше двух") {
+		t.Errorf("MOGWO принял одну целевую функцию: %v", err)
+	}
+	if algorithm, err := NewMOFA(FARequest{AlgoRequest: multi}); err != nil {
+		t.Errorf("MOFA: %v", err)
+	} else {
+		algorithm.Close()
+	}
+}
+
+// сохранённые значения агентов многокритериальных алгоритмов совпадают с первым критерием
+func TestMultiObjectiveFitness(t *testing.T) {
+	constructors := map[string]func(AlgoRequest) (Algorithm, error){
+		"MOGWO": func(r AlgoRequest) (Algorithm, error) { return NewMOGWO(GWORequest{AlgoRequest: r}) },
+		"MOFA":  func(r AlgoRequest) (Algorithm, error) { return NewMOFA(FARequest{AlgoRequest: r}) },
+		"MOABC": func(r AlgoRequest) (Algorithm, error) { return NewMOABC(ABCRequest{AlgoRequest: r}) },
+	}
+	for name, constructor := range constructors {
+		size, seed, dimensions := 8, 1, 2
+		algorithm, err := constructor(AlgoRequest{
+			Objectives: []string{"x^2 + y^2", "(x-2)^2 + y^2"}, Iterations: 5,
+			PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		algorithm.Init(context.Background())
+		for {
+			if _, ok := algorithm.Step(); !ok {
+				break
+			}
+		}
+		snapshot := algorithm.Checkpoint()
+		for i, position := range snapshot.Population {
+			if want := position[0]*position[0] + position[1]*position[1]; math.Abs(snapshot.Fitness[i]-want) > 1e-12 {
+				t.Errorf("%s: Fitness[%d] = %v, want %v", name, i, snapshot.Fitness[i], want)
+			}
+		}
+		algorithm.Close()
+	}
+}
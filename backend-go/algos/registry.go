@@ -0,0 +1,153 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// NativeFunction — целевая функция, написанная на Go. Её регистрируют
+// по имени до запуска сервера или CLI, а в запросах выбирают полем nativeFunction.
+// Func и Batch вызываются из нескольких горутин одновременно.
+type NativeFunction struct {
+	Func func([]float64) float64
+	// вычисление сразу в нескольких точках, например на GPU; необязательно
+	Batch func([][]float64) []float64
+	// значение и градиент для локального поиска; необязательно
+	Gradient func([]float64) (float64, []float64)
+
+	// границы поиска по умолчанию: одна пара для всех координат или по паре на координату
+	Bounds [][]float64
+	// фиксированная размерность; 0 — функция определена для любой размерности
+	Dimensions int
+	// глобальный минимум для размерности n, как у тестовых функций; необязательно
+	Optimum func(n int) (position []float64, value float64, ok bool)
+
+	Description string
+	Metadata    map[string]string
+}
+
+// FunctionInfo — описание зарегистрированной функции для клиентов
+type FunctionInfo struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Dimensions  int               `json:"dimensions,omitempty"`
+	Bounds      [][]float64       `json:"bounds,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Batch       bool              `json:"batch"`
+	Gradient    bool              `json:"gradient"`
+}
+
+var (
+	nativeMu        sync.RWMutex
+	nativeFunctions = map[string]NativeFunction{}
+)
+
+// RegisterFunction добавляет целевую функцию в реестр
+func RegisterFunction(name string, function NativeFunction) error {
+	if name == "" {
+		return errors.New("имя функции не задано")
+	}
+	if function.Func == nil {
+		return fmt.Errorf("функция %q не задана", name)
+	}
+	if function.Dimensions < 0 {
+		return fmt.Errorf("функция %q: размерность не может быть отрицательной", name)
+	}
+	if len(function.Bounds) > 1 && len(function.Bounds) != function.Dimensions {
+		return fmt.Errorf("функция %q: границы заданы не для каждой координаты", name)
+	}
+	for _, bound := range function.Bounds {
+		if len(bound) != 2 || !(bound[0] < bound[1]) {
+			return fmt.Errorf("функция %q: неверно заданы границы поиска", name)
+		}
+	}
+
+	nativeMu.Lock()
+	defer nativeMu.Unlock()
+	if _, ok := nativeFunctions[name]; ok {
+		return fmt.Errorf("функция %q уже зарегистрирована", name)
+	}
+	nativeFunctions[name] = function
+	return nil
+}
+
+// MustRegisterFunction — RegisterFunction для регистрации при инициализации пакета
+func MustRegisterFunction(name string, function NativeFunction) {
+	if err := RegisterFunction(name, function); err != nil {
+		panic(err)
+	}
+}
+
+func LookupFunction(name string) (NativeFunction, bool) {
+	nativeMu.RLock()
+	defer nativeMu.RUnlock()
+	function, ok := nativeFunctions[name]
+	return function, ok
+}
+
+// RegisteredFunctions возвращает описания зарегистрированных функций по алфавиту
+func RegisteredFunctions() []FunctionInfo {
+	nativeMu.RLock()
+	defer nativeMu.RUnlock()
+
+	infos := make([]FunctionInfo, 0, len(nativeFunctions))
+	for name, function := range nativeFunctions {
+		infos = append(infos, FunctionInfo{
+			Name:        name,
+			Description: function.Description,
+			Dimensions:  function.Dimensions,
+			Bounds:      function.Bounds,
+			Metadata:    function.Metadata,
+			Batch:       function.Batch != nil,
+			Gradient:    function.Gradient != nil,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// nativeBatch передаёт пакетный вариант зарегистрированной функции в evaluatePopulation
+type nativeBatch struct {
+	name     string
+	function NativeFunction
+	batchErrors
+}
+
+// Evaluate вычисляет пакет; если функция вернула не столько значений, сколько
+// точек, значения пакета — +Inf, как у внешних вычислителей
+func (b *nativeBatch) Evaluate(positions [][]float64) []float64 {
+	values := b.function.Batch(positions)
+	if len(values) != len(positions) {
+		b.record(fmt.Errorf("функция %q вернула %d значений вместо %d", b.name, len(values), len(positions)))
+		values = make([]float64, len(positions))
+		for i := range values {
+			values[i] = math.Inf(1)
+		}
+	}
+	return values
+}
+
+func (b *nativeBatch) Eval(position []float64) float64 {
+	return b.function.Func(position)
+}
+
+func (b *nativeBatch) Close() error {
+	return nil
+}
+
+// границы поиска функции для размерности n
+func (f NativeFunction) bounds(n int) [][]float64 {
+	bounds := make([][]float64, n)
+	for i := range bounds {
+		bound := f.Bounds[0]
+		if len(f.Bounds) > 1 {
+			bound = f.Bounds[i]
+		}
+		bounds[i] = slices.Clone(bound)
+	}
+	return bounds
+}
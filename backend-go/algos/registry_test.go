@@ -0,0 +1,58 @@
+package algos
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// пакетная функция, вернувшая меньше значений, чем точек, не роняет алгоритм:
+// значения пакета — +Inf, а ошибка попадает в ответ
+func TestNativeShortBatch(t *testing.T) {
+	// первый пакет — начальная популяция — вычисляется верно, остальные короче на одно значение
+	var calls atomic.Int32
+	MustRegisterFunction("test-short-batch", NativeFunction{
+		Func: func(x []float64) float64 { return x[0] * x[0] },
+		Batch: func(positions [][]float64) []float64 {
+			values := make([]float64, len(positions))
+			if calls.Add(1) > 1 {
+				values = values[1:]
+			}
+			return values
+		},
+	})
+	size, seed, dimensions := 5, 1, 2
+	request := func() AlgoRequest {
+		return AlgoRequest{
+			Native: "test-short-batch", Iterations: 3, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+			Bounds: [][]float64{{-1, 1}, {-1, 1}},
+		}
+	}
+	gwo, err := newGWO(GWORequest{AlgoRequest: request()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gwo.Close()
+	gwo.Init(context.Background())
+	response, _ := gwo.Step()
+	if !strings.Contains(response.Error, "вернула 4 значений вместо 5") {
+		t.Errorf("ошибка в ответе: %q", response.Error)
+	}
+	gwo.Run(context.Background(), func(Response) error { return nil })
+
+	native, _ := LookupFunction("test-short-batch")
+	batch := &nativeBatch{name: "test-short-batch", function: native}
+	if values := batch.Evaluate([][]float64{{1, 0}, {0, 1}}); len(values) != 2 || !math.IsInf(values[0], 1) || !math.IsInf(values[1], 1) {
+		t.Errorf("Evaluate = %v, want [+Inf +Inf]", values)
+	}
+	if batch.Err() == nil {
+		t.Error("ошибка короткого пакета не сохранена")
+	}
+
+	// без единого вычисленного значения задача не создаётся
+	if _, err := NewGWO(GWORequest{AlgoRequest: request()}); err == nil {
+		t.Error("ожидалась ошибка: начальная популяция не вычислена")
+	}
+}
@@ -0,0 +1,221 @@
+package algos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type HTTPObjectiveRequest struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// время ожидания одного запроса, в секундах
+	Timeout *float64 `json:"timeout,omitempty"`
+	// число повторов запроса при сетевой ошибке или ответе 5xx/429
+	Retries *int `json:"retries,omitempty"`
+	// наибольшее число точек в одном запросе; 0 — вся популяция сразу
+	BatchSize *int `json:"batchSize,omitempty"`
+}
+
+// HTTPObjective вычисляет целевую функцию на HTTP-сервере: POST с телом
+// {"positions": [[...], ...]}, ответ {"values": [...]} или {"error": "..."}.
+type HTTPObjective struct {
+	URL       string
+	Headers   map[string]string
+	Retries   int
+	BatchSize int
+
+	client *http.Client
+	// запросы прерываются при отмене работы алгоритма
+	clock *Clock
+	batchErrors
+}
+
+var (
+	httpMu      sync.RWMutex
+	allowedURLs = map[string]bool{}
+)
+
+// AllowHTTPObjective разрешает обращаться к серверам целевой функции по адресам urls.
+// Запросы с другими адресами отклоняются, чтобы клиент не мог отправлять
+// POST-запросы от имени сервера на произвольные адреса.
+func AllowHTTPObjective(urls ...string) error {
+	for _, address := range urls {
+		if u, err := url.Parse(address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("неверный адрес сервера целевой функции %q", address)
+		}
+	}
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	for _, address := range urls {
+		allowedURLs[address] = true
+	}
+	return nil
+}
+
+func httpAllowed(address string) bool {
+	httpMu.RLock()
+	defer httpMu.RUnlock()
+	return allowedURLs[address]
+}
+
+// ошибка, повтор запроса при которой не поможет
+type permanentError struct {
+	error
+}
+
+func NewHTTPObjective(request HTTPObjectiveRequest) (*HTTPObjective, error) {
+	remote := &HTTPObjective{
+		URL:       request.URL,
+		Headers:   request.Headers,
+		Retries:   setDefault(request.Retries, 3),
+		BatchSize: setDefault(request.BatchSize, 0),
+	}
+	timeout := time.Duration(setDefault(request.Timeout, 10.0) * float64(time.Second))
+
+	if u, err := url.Parse(remote.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("неверный адрес сервера целевой функции %q", request.URL)
+	}
+	if !httpAllowed(remote.URL) {
+		return nil, fmt.Errorf("адрес сервера целевой функции %q не разрешён сервером", request.URL)
+	}
+	if timeout <= 0 {
+		return nil, errors.New("время ожидания должно быть больше 0")
+	}
+	if remote.Retries < 0 {
+		return nil, errors.New("число повторов должно быть неотрицательным")
+	}
+	if remote.BatchSize < 0 {
+		return nil, errors.New("размер пакета должен быть неотрицательным")
+	}
+
+	remote.client = &http.Client{
+		Timeout: timeout,
+		// перенаправление тоже может вести только на разрешённый адрес
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if !httpAllowed(request.URL.String()) {
+				return fmt.Errorf("перенаправление на неразрешённый адрес %q", request.URL)
+			}
+			if len(via) >= 10 {
+				return errors.New("слишком много перенаправлений")
+			}
+			return nil
+		},
+	}
+	return remote, nil
+}
+
+func (h *HTTPObjective) post(ctx context.Context, positions [][]float64) ([]float64, error) {
+	body, _ := json.Marshal(batchRequest{Positions: positions})
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, permanentError{err}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range h.Headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := h.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("сервер целевой функции ответил %s", response.Status)
+		if response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests {
+			return nil, err
+		}
+		return nil, permanentError{err}
+	}
+
+	var result batchResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, permanentError{fmt.Errorf("неверный ответ сервера целевой функции: %w", err)}
+	}
+	if result.Error != "" {
+		return nil, permanentError{errors.New("сервер целевой функции вернул ошибку: " + result.Error)}
+	}
+	if len(result.Values) != len(positions) {
+		return nil, permanentError{fmt.Errorf("сервер целевой функции вернул %d значений вместо %d", len(result.Values), len(positions))}
+	}
+	return result.Values, nil
+}
+
+// EvaluateBatch отправляет точки одним запросом, повторяя его
+// с экспоненциальной задержкой при временных сбоях; отмена работы алгоритма
+// прерывает и запрос, и ожидание повтора
+func (h *HTTPObjective) EvaluateBatch(positions [][]float64) ([]float64, error) {
+	ctx := h.clock.runContext()
+	delay := 100 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		values, err := h.post(ctx, positions)
+		if err == nil {
+			return values, nil
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) || attempt == h.Retries {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Evaluate отправляет точки пакетами по BatchSize;
+// при ошибке значения пакета — +Inf, как при ошибке вычисления выражения.
+// После отмены работы алгоритма пакеты не отправляются, а их значения — +Inf.
+func (h *HTTPObjective) Evaluate(positions [][]float64) []float64 {
+	ctx := h.clock.runContext()
+	size := h.BatchSize
+	if size == 0 {
+		size = len(positions)
+	}
+
+	values := make([]float64, 0, len(positions))
+	for start := 0; start < len(positions); start += size {
+		end := min(start+size, len(positions))
+		var batch []float64
+		err := ctx.Err()
+		if err == nil {
+			batch, err = h.EvaluateBatch(positions[start:end])
+		}
+		if err != nil {
+			// отмена — не ошибка целевой функции, о ней сообщает причина остановки
+			if ctx.Err() == nil {
+				h.record(err)
+			}
+			for range end - start {
+				batch = append(batch, math.Inf(1))
+			}
+		}
+		values = append(values, batch...)
+	}
+	return values
+}
+
+func (h *HTTPObjective) Eval(position []float64) float64 {
+	return h.Evaluate([][]float64{position})[0]
+}
+
+func (h *HTTPObjective) Close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
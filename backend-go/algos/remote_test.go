@@ -0,0 +1,166 @@
+package algos
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// сервер возвращает сумму координат, а для точек с отрицательной первой координатой — ошибку
+func newSumServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/evaluate", http.StatusTemporaryRedirect)
+			return
+		}
+		var request batchRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		response := batchResponse{}
+		for _, position := range request.Positions {
+			if position[0] < 0 {
+				response = batchResponse{Error: "отрицательная координата"}
+				break
+			}
+			response.Values = append(response.Values, position[0]+position[1])
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPObjectiveAllowlist(t *testing.T) {
+	server := newSumServer(t)
+	if _, err := NewHTTPObjective(HTTPObjectiveRequest{URL: server.URL + "/evaluate"}); err == nil || !strings.Contains(err.Error(), "не разрешён") {
+		t.Errorf("принят неразрешённый адрес: %v", err)
+	}
+
+	if err := AllowHTTPObjective(server.URL + "/redirect"); err != nil {
+		t.Fatal(err)
+	}
+	retries := 0
+	remote, err := NewHTTPObjective(HTTPObjectiveRequest{URL: server.URL + "/redirect", Retries: &retries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+	// перенаправление на неразрешённый адрес не выполняется
+	if got := remote.Eval([]float64{1, 2}); !math.IsInf(got, 1) {
+		t.Errorf("Eval = %v после перенаправления на неразрешённый адрес, want +Inf", got)
+	}
+	if err := remote.Err(); err == nil || !strings.Contains(err.Error(), "перенаправление") {
+		t.Errorf("Err = %v", err)
+	}
+}
+
+// ошибка пакета даёт +Inf, в том числе при максимизации, и попадает в ответ
+func TestHTTPObjectiveFailure(t *testing.T) {
+	server := newSumServer(t)
+	address := server.URL + "/evaluate"
+	if err := AllowHTTPObjective(address); err != nil {
+		t.Fatal(err)
+	}
+	batchSize, retries := 1, 0
+	remote, err := NewHTTPObjective(HTTPObjectiveRequest{URL: address, BatchSize: &batchSize, Retries: &retries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := remote.Evaluate([][]float64{{1, 2}, {-1, 2}})
+	if values[0] != 3 || !math.IsInf(values[1], 1) {
+		t.Errorf("Evaluate = %v, want [3 +Inf]", values)
+	}
+	if err := remote.Err(); err == nil {
+		t.Error("ошибка пакета не сохранена")
+	}
+	if err := remote.Err(); err != nil {
+		t.Errorf("ошибка не сброшена: %v", err)
+	}
+	remote.Close()
+
+	size, seed, dimensions := 6, 1, 2
+	gwo, err := NewGWO(GWORequest{AlgoRequest: AlgoRequest{
+		HTTP: &HTTPObjectiveRequest{URL: address, Retries: &retries, BatchSize: &batchSize}, Direction: "max",
+		Iterations: 3, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+		Bounds: [][]float64{{-1, 1}, {-1, 1}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gwo.Close()
+	response := gwo.Init(context.Background())
+	if response.Error == "" {
+		t.Error("ошибка сервера не попала в ответ")
+	}
+	if math.IsInf(response.BestValue, 0) {
+		t.Errorf("лучшее значение %v: ошибка вычисления принята за решение", response.BestValue)
+	}
+}
+
+// отмена работы алгоритма прерывает зависший запрос и ожидание повтора,
+// а следующие пакеты не отправляются
+func TestHTTPObjectiveCancel(t *testing.T) {
+	var hang atomic.Bool
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// сервер замечает разрыв соединения только после чтения тела запроса
+		io.ReadAll(r.Body)
+		requests.Add(1)
+		if hang.Load() {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	if err := AllowHTTPObjective(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		hang bool
+		// отмена через 250 мс приходится на ожидание третьего повтора: задержки 100 и 200 мс
+		maxRequests int32
+	}{
+		{"зависший запрос", true, 1},
+		{"ожидание повтора", false, 3},
+	}
+	batchSize, retries := 1, 5
+	for _, test := range tests {
+		remote, err := NewHTTPObjective(HTTPObjectiveRequest{URL: server.URL, BatchSize: &batchSize, Retries: &retries})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+		remote.clock = &Clock{ctx: ctx}
+		hang.Store(test.hang)
+		requests.Store(0)
+
+		start := time.Now()
+		values := remote.Evaluate([][]float64{{1, 2}, {3, 4}, {5, 6}})
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s: Evaluate после отмены ждал %v", test.name, elapsed)
+		}
+		for _, value := range values {
+			if !math.IsInf(value, 1) {
+				t.Errorf("%s: Evaluate = %v, want +Inf", test.name, values)
+				break
+			}
+		}
+		if n := requests.Load(); n > test.maxRequests {
+			t.Errorf("%s: отправлено %d запросов, want не больше %d", test.name, n, test.maxRequests)
+		}
+		if err := remote.Err(); err != nil {
+			t.Errorf("%s: отмена сохранена как ошибка функции: %v", test.name, err)
+		}
+		cancel()
+		remote.Close()
+	}
+}
@@ -0,0 +1,179 @@
+package algos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+type StoppingRequest struct {
+	// остановиться, когда лучшее значение достигнет target (с учётом направления оптимизации)
+	Target *float64 `json:"target,omitempty"`
+	// остановиться, если за window итераций лучшее значение улучшилось меньше чем на tolerance
+	Tolerance *float64 `json:"tolerance,omitempty"`
+	Window    *int     `json:"window,omitempty"`
+	// остановиться, когда наибольшее расстояние между агентами меньше diameter
+	Diameter *float64 `json:"diameter,omitempty"`
+}
+
+// причины остановки алгоритма
+const (
+	StopIterations  = "maxIterations"
+	StopEvaluations = "maxEvaluations"
+	StopTimeLimit   = "timeLimit"
+	StopCancelled   = "cancelled"
+	StopTarget      = "target"
+	StopStagnation  = "stagnation"
+	StopDiameter    = "diameter"
+)
+
+var stopMessages = map[string]string{
+	StopIterations:  "выполнено заданное число итераций",
+	StopEvaluations: "израсходован бюджет вычислений целевой функции",
+	StopTimeLimit:   "истекло время работы",
+	StopCancelled:   "работа прервана",
+	StopTarget:      "достигнуто целевое значение",
+	StopStagnation:  "лучшее значение перестало улучшаться",
+	StopDiameter:    "популяция сошлась",
+}
+
+// StoppingCriterion проверяет после каждой итерации, пора ли остановить алгоритм.
+// Критерии объединяются по «или»: алгоритм останавливается по первому сработавшему.
+// Бюджет вычислений и ограничение времени проверяются отдельно, внутри итераций.
+type StoppingCriterion interface {
+	// Stop возвращает причину остановки или пустую строку
+	Stop(algo *Algo) string
+}
+
+// TargetCriterion останавливает алгоритм, когда допустимое лучшее решение
+// не хуже Value; Value задаётся в знаке целевой функции пользователя
+type TargetCriterion struct {
+	Value float64
+}
+
+func (c TargetCriterion) Stop(algo *Algo) string {
+	if algo.GlobalBestPosition == nil {
+		return ""
+	}
+	if algo.Constraints != nil && algo.Constraints.Violation(algo.GlobalBestPosition) > 0 {
+		return ""
+	}
+	if algo.GlobalBestValue <= algo.userValue(c.Value) {
+		return StopTarget
+	}
+	return ""
+}
+
+// StagnationCriterion останавливает алгоритм, если за Window итераций лучшее
+// значение улучшилось меньше чем на Tolerance
+type StagnationCriterion struct {
+	Tolerance float64
+	Window    int
+}
+
+func (c StagnationCriterion) Stop(algo *Algo) string {
+	history := algo.History
+	if len(history) <= c.Window {
+		return ""
+	}
+	past, current := history[len(history)-1-c.Window], history[len(history)-1]
+	// до первого вычисленного значения улучшение не определено
+	if math.IsInf(past, 1) {
+		return ""
+	}
+	if past-current < c.Tolerance {
+		return StopStagnation
+	}
+	return ""
+}
+
+// DiameterCriterion останавливает алгоритм, когда наибольшее расстояние
+// между агентами меньше Diameter
+type DiameterCriterion struct {
+	Diameter float64
+}
+
+func (c DiameterCriterion) Stop(algo *Algo) string {
+	limit := c.Diameter * c.Diameter
+	for i, a := range algo.Population {
+		for _, b := range algo.Population[i+1:] {
+			distance := 0.0
+			for j := range a {
+				distance += (a[j] - b[j]) * (a[j] - b[j])
+			}
+			if distance >= limit {
+				return ""
+			}
+		}
+	}
+	return StopDiameter
+}
+
+func NewStoppingCriteria(request StoppingRequest) ([]StoppingCriterion, error) {
+	var criteria []StoppingCriterion
+	if request.Target != nil {
+		criteria = append(criteria, TargetCriterion{Value: *request.Target})
+	}
+	if request.Tolerance != nil || request.Window != nil {
+		criterion := StagnationCriterion{
+			Tolerance: setDefault(request.Tolerance, 1e-8),
+			Window:    setDefault(request.Window, 10),
+		}
+		if criterion.Tolerance < 0 {
+			return nil, errors.New("допуск улучшения не может быть отрицательным")
+		}
+		if criterion.Window < 1 {
+			return nil, errors.New("окно проверки улучшения должно быть больше 0")
+		}
+		criteria = append(criteria, criterion)
+	}
+	if request.Diameter != nil {
+		if *request.Diameter <= 0 {
+			return nil, errors.New("диаметр популяции должен быть больше 0")
+		}
+		criteria = append(criteria, DiameterCriterion{Diameter: *request.Diameter})
+	}
+	return criteria, nil
+}
+
+// checkStopping запоминает лучшее значение после итерации и проверяет критерии остановки
+func (algo *Algo) checkStopping() {
+	algo.History = append(algo.History, algo.GlobalBestValue)
+	if algo.stopped != "" {
+		return
+	}
+	for _, criterion := range algo.Stopping {
+		if reason := criterion.Stop(algo); reason != "" {
+			algo.stopped = reason
+			return
+		}
+	}
+}
+
+// stopReason возвращает причину, по которой ответ на этой итерации последний
+func (algo *Algo) stopReason(iteration int) string {
+	switch {
+	case algo.stopped != "":
+		return algo.stopped
+	case algo.Clock.budget > 0 && int64(algo.Clock.Evaluations()) >= algo.Clock.budget:
+		return StopEvaluations
+	case algo.Clock.ctx != nil && errors.Is(algo.Clock.ctx.Err(), context.DeadlineExceeded):
+		return StopTimeLimit
+	case algo.Clock.cancelled():
+		return StopCancelled
+	case iteration >= algo.Iterations:
+		return StopIterations
+	}
+	return ""
+}
+
+// summary — итог работы для последнего ответа
+func summary(reason string, response Response) string {
+	message, ok := stopMessages[reason]
+	if !ok {
+		message = reason
+	}
+	return fmt.Sprintf("Остановка: %s. Итераций: %d, вычислений: %d, лучшее значение: %g",
+		message, response.Iteration, response.Evaluations, response.BestValue)
+}
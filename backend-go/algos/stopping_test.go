@@ -0,0 +1,125 @@
+package algos
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestStoppingCriteria(t *testing.T) {
+	inf := math.Inf(1)
+	tests := []struct {
+		name      string
+		criterion StoppingCriterion
+		algo      Algo
+		want      string
+	}{
+		{"цель достигнута", TargetCriterion{Value: 0.1}, Algo{GlobalBestPosition: []float64{0}, GlobalBestValue: 0.05}, StopTarget},
+		{"цель не достигнута", TargetCriterion{Value: 0.1}, Algo{GlobalBestPosition: []float64{0}, GlobalBestValue: 0.2}, ""},
+		{"цель при максимизации", TargetCriterion{Value: 5}, Algo{GlobalBestPosition: []float64{0}, GlobalBestValue: -6, Maximize: true}, StopTarget},
+		{"цель без лучшего решения", TargetCriterion{Value: 0.1}, Algo{GlobalBestValue: inf}, ""},
+		{"застой", StagnationCriterion{Tolerance: 0.1, Window: 2}, Algo{History: []float64{5, 1, 0.95, 0.93}}, StopStagnation},
+		{"улучшение в окне", StagnationCriterion{Tolerance: 0.1, Window: 2}, Algo{History: []float64{5, 1, 0.5, 0.45}}, ""},
+		{"история короче окна", StagnationCriterion{Tolerance: 0.1, Window: 3}, Algo{History: []float64{1, 1, 1}}, ""},
+		{"до первого значения", StagnationCriterion{Tolerance: 0.1, Window: 1}, Algo{History: []float64{inf, inf}}, ""},
+		{"популяция сошлась", DiameterCriterion{Diameter: 0.5}, Algo{Population: [][]float64{{0, 0}, {0.3, 0}, {0, 0.3}}}, StopDiameter},
+		{"популяция не сошлась", DiameterCriterion{Diameter: 0.5}, Algo{Population: [][]float64{{0, 0}, {0.3, 0}, {0.3, 0.4}}}, ""},
+	}
+	for _, test := range tests {
+		if got := test.criterion.Stop(&test.algo); got != test.want {
+			t.Errorf("%s: Stop = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestStoppingRequestErrors(t *testing.T) {
+	negative, zero := -1.0, 0
+	zeroDiameter := 0.0
+	for _, request := range []StoppingRequest{{Tolerance: &negative}, {Window: &zero}, {Diameter: &zeroDiameter}} {
+		if _, err := NewStoppingCriteria(request); err == nil {
+			t.Errorf("%+v: ожидалась ошибка", request)
+		}
+	}
+}
+
+// алгоритм останавливается раньше заданного числа итераций и сообщает причину
+func TestStoppingTarget(t *testing.T) {
+	size, seed, dimensions := 10, 1, 2
+	target := 1e-2
+	gwo, err := NewGWO(GWORequest{AlgoRequest: AlgoRequest{
+		Func: "x^2 + y^2", Iterations: 100, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+		Stopping: &StoppingRequest{Target: &target},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gwo.Close()
+	response := gwo.Init(context.Background())
+	iterations := 0
+	for {
+		next, ok := gwo.Step()
+		if !ok {
+			break
+		}
+		response = next
+		iterations++
+	}
+	if response.StopReason != StopTarget || iterations >= 100 {
+		t.Errorf("остановка %q после %d итераций", response.StopReason, iterations)
+	}
+	if response.BestValue > target {
+		t.Errorf("лучшее значение %v хуже цели %v", response.BestValue, target)
+	}
+}
+
+// каждый алгоритм расходует бюджет вычислений целиком и не превышает его
+func TestEvaluationBudget(t *testing.T) {
+	// при таком бюджете он кончается посреди перелётов светлячка MOFA
+	size, seed, dimensions, budget := 10, 1, 3, 37
+	request := func(function string) AlgoRequest {
+		return AlgoRequest{
+			Benchmark: function, Iterations: 1000, PopulationSize: &size, Seed: &seed, NumDimensions: &dimensions,
+			MaxEvaluations: &budget,
+		}
+	}
+	tests := []struct {
+		name string
+		algo func() (Algorithm, error)
+	}{
+		{"GWO", func() (Algorithm, error) { return NewGWO(GWORequest{AlgoRequest: request("sphere")}) }},
+		{"ABC", func() (Algorithm, error) { return NewABC(ABCRequest{AlgoRequest: request("sphere")}) }},
+		{"AFSA", func() (Algorithm, error) {
+			return NewAFSA(AFSARequest{AlgoRequest: request("sphere"), Visual: []float64{0.1, 0.5}})
+		}},
+		{"FA", func() (Algorithm, error) { return NewFA(FARequest{AlgoRequest: request("sphere")}) }},
+		{"SFLA", func() (Algorithm, error) { return NewSFLA(SFLARequest{AlgoRequest: request("sphere")}) }},
+		{"MOGWO", func() (Algorithm, error) { return NewMOGWO(GWORequest{AlgoRequest: request("zdt1")}) }},
+		{"MOABC", func() (Algorithm, error) { return NewMOABC(ABCRequest{AlgoRequest: request("zdt1")}) }},
+		// светлячок летит к каждому доминирующему соседу, вычисляя функцию на каждом перелёте
+		{"MOFA", func() (Algorithm, error) { return NewMOFA(FARequest{AlgoRequest: request("zdt1")}) }},
+	}
+	for _, test := range tests {
+		algo, err := test.algo()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		var last Response
+		algo.Run(context.Background(), func(response Response) error {
+			last = response
+			return nil
+		})
+		algo.Close()
+		// агент не переходит в точку, где функция уже не вычислялась
+		if mofa, ok := algo.(*MOFA); ok {
+			for i, values := range mofa.values {
+				if math.IsInf(values[0], 1) {
+					t.Errorf("%s: агент %d в невычисленной точке %v", test.name, i, mofa.Population[i])
+				}
+			}
+		}
+		if last.Evaluations != budget || last.StopReason != StopEvaluations {
+			t.Errorf("%s: %d вычислений из %d, остановка %q", test.name, last.Evaluations, budget, last.StopReason)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package algos
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+type TransformRequest struct {
+	// положение оптимума сдвигается на вектор shift
+	Shift []float64 `json:"shift,omitempty"`
+	// оптимум переносится в случайную точку в пределах 80% области поиска
+	RandomShift bool `json:"randomShift,omitempty"`
+	// случайный ортогональный поворот вокруг оптимума
+	Rotate bool `json:"rotate,omitempty"`
+	// seed для случайного сдвига и поворота; по умолчанию — производный от seed запроса
+	Seed *int `json:"seed,omitempty"`
+	// масштаб по каждой координате
+	Scale []float64 `json:"scale,omitempty"`
+	// добавка к значению функции
+	Bias float64 `json:"bias,omitempty"`
+}
+
+// Transform — преобразование задачи в стиле CEC: f(M·(s ⊙ (x − o)) + x*) + bias,
+// где x* — оптимум исходной функции (Center). Поворот и масштаб выполняются вокруг
+// оптимума, и он переходит в точку o (Shift) пространства поиска.
+type Transform struct {
+	Shift    []float64
+	Rotation [][]float64
+	Scale    []float64
+	Bias     float64
+	// оптимум исходной функции; nil — начало координат
+	Center []float64
+}
+
+// NewTransform создаёт преобразование; center — оптимум исходной функции или nil,
+// если он неизвестен и преобразование выполняется вокруг начала координат
+func NewTransform(request TransformRequest, bounds [][]float64, center []float64, seed int64) (*Transform, error) {
+	n := len(bounds)
+	transform := &Transform{Bias: request.Bias, Center: center}
+	if request.Seed != nil {
+		seed = int64(*request.Seed)
+	}
+	rng := rand.New(newGenerator(seed))
+
+	switch {
+	case request.Shift != nil && request.RandomShift:
+		return nil, errors.New("сдвиг задан одновременно вектором и случайно")
+	case request.Shift != nil:
+		if len(request.Shift) != n {
+			return nil, errors.New("несоответствие размерности сдвига и размерности задачи")
+		}
+		transform.Shift = make([]float64, n)
+		for j, shift := range request.Shift {
+			transform.Shift[j] = shift
+			if center != nil {
+				transform.Shift[j] += center[j]
+			}
+		}
+	case request.RandomShift:
+		// новое положение оптимума
+		transform.Shift = make([]float64, n)
+		for j, bound := range bounds {
+			center, half := (bound[0]+bound[1])/2, (bound[1]-bound[0])/2
+			transform.Shift[j] = center + 0.8*half*(2*rng.Float64()-1)
+		}
+	case center != nil:
+		transform.Shift = center
+	}
+
+	if request.Scale != nil {
+		if len(request.Scale) != n {
+			return nil, errors.New("несоответствие размерности масштаба и размерности задачи")
+		}
+		for _, s := range request.Scale {
+			if s == 0 {
+				return nil, errors.New("масштаб по координате не может быть нулевым")
+			}
+		}
+		transform.Scale = request.Scale
+	}
+
+	if request.Rotate {
+		transform.Rotation = randomRotation(n, rng)
+	}
+	return transform, nil
+}
+
+// randomRotation строит случайную ортогональную матрицу ортогонализацией
+// Грама — Шмидта матрицы с нормальными элементами
+func randomRotation(n int, rng *rand.Rand) [][]float64 {
+	rotation := make([][]float64, n)
+	for i := range rotation {
+		for {
+			row := make([]float64, n)
+			for j := range row {
+				row[j] = rng.NormFloat64()
+			}
+			for _, previous := range rotation[:i] {
+				dot := 0.0
+				for j := range row {
+					dot += row[j] * previous[j]
+				}
+				for j := range row {
+					row[j] -= dot * previous[j]
+				}
+			}
+			norm := 0.0
+			for _, v := range row {
+				norm += v * v
+			}
+			// почти линейно зависимая строка — выбираем другую
+			if norm = math.Sqrt(norm); norm > 1e-8 {
+				for j := range row {
+					row[j] /= norm
+				}
+				rotation[i] = row
+				break
+			}
+		}
+	}
+	return rotation
+}
+
+// Apply переводит точку пространства поиска в аргумент исходной функции
+func (t *Transform) Apply(x []float64) []float64 {
+	z := make([]float64, len(x))
+	copy(z, x)
+	for j := range z {
+		if t.Shift != nil {
+			z[j] -= t.Shift[j]
+		}
+		if t.Scale != nil {
+			z[j] *= t.Scale[j]
+		}
+	}
+	if t.Rotation != nil {
+		rotated := make([]float64, len(z))
+		for i, row := range t.Rotation {
+			for j, m := range row {
+				rotated[i] += m * z[j]
+			}
+		}
+		z = rotated
+	}
+	if t.Center != nil {
+		for j := range z {
+			z[j] += t.Center[j]
+		}
+	}
+	return z
+}
+
+// Inverse находит точку пространства поиска, которая переходит в z
+func (t *Transform) Inverse(z []float64) []float64 {
+	y := make([]float64, len(z))
+	copy(y, z)
+	if t.Center != nil {
+		for j := range y {
+			y[j] -= t.Center[j]
+		}
+	}
+	x := y
+	if t.Rotation != nil {
+		// обратная к ортогональной матрице — транспонированная
+		x = make([]float64, len(y))
+		for i, row := range t.Rotation {
+			for j, m := range row {
+				x[j] += m * y[i]
+			}
+		}
+	}
+	for j := range x {
+		if t.Scale != nil {
+			x[j] /= t.Scale[j]
+		}
+		if t.Shift != nil {
+			x[j] += t.Shift[j]
+		}
+	}
+	return x
+}
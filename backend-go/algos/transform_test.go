@@ -0,0 +1,92 @@
+package algos
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// оптимум преобразованной тестовой функции остаётся в области поиска,
+// и значение функции в нём совпадает с известным (оптимумы даны с 4 знаками)
+func TestTransformKeepsOptimumInBounds(t *testing.T) {
+	seed, size, dimensions := 3, 5, 2
+	for _, benchmark := range []string{"schwefel", "eggholder", "michalewicz", "rastrigin", "rosenbrock"} {
+		algo, err := NewAlgo(AlgoRequest{
+			Benchmark: benchmark, Iterations: 1, Seed: &seed, PopulationSize: &size, NumDimensions: &dimensions,
+			Transform: &TransformRequest{Rotate: true, RandomShift: true},
+		})
+		if err != nil {
+			t.Errorf("%s: %v", benchmark, err)
+			continue
+		}
+		for j, bound := range algo.Bounds {
+			if x := algo.OptimumPosition[j]; x < bound[0] || x > bound[1] {
+				t.Errorf("%s: оптимум %v вне границ %v", benchmark, algo.OptimumPosition, algo.Bounds)
+				break
+			}
+		}
+		if value := algo.exactObjective(algo.OptimumPosition); math.Abs(value-*algo.OptimumValue) > 1e-3 {
+			t.Errorf("%s: f(оптимум) = %v, want %v", benchmark, value, *algo.OptimumValue)
+		}
+		algo.Close()
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	dimensions, size := 2, 5
+	tests := []struct {
+		name    string
+		request AlgoRequest
+		want    string
+	}{
+		{"поворот без известного оптимума", AlgoRequest{Benchmark: "zdt1", Transform: &TransformRequest{Rotate: true}}, "известным оптимумом"},
+		{"поворот при максимизации", AlgoRequest{Benchmark: "sphere", Direction: "max", Transform: &TransformRequest{Rotate: true}}, "известным оптимумом"},
+		{"сдвиг за границы", AlgoRequest{Benchmark: "schwefel", Transform: &TransformRequest{Shift: []float64{100, 0}}}, "вне границ"},
+	}
+	for _, test := range tests {
+		test.request.Iterations = 1
+		test.request.NumDimensions = &dimensions
+		test.request.PopulationSize = &size
+		if _, err := NewAlgo(test.request); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: %v, ожидалась ошибка %q", test.name, err, test.want)
+		}
+	}
+
+	// выражение поворачивается вокруг начала координат
+	algo, err := NewAlgo(AlgoRequest{Func: "x^2 + y^2", Iterations: 1, NumDimensions: &dimensions, PopulationSize: &size, Transform: &TransformRequest{Rotate: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	algo.Close()
+}
+
+func TestTransformInverse(t *testing.T) {
+	bounds := [][]float64{{-5, 5}, {-5, 5}, {-5, 5}}
+	requests := []TransformRequest{
+		{Rotate: true},
+		{RandomShift: true, Scale: []float64{2, 0.5, -1}},
+		{Shift: []float64{1, 2, 3}, Rotate: true, Scale: []float64{1, 3, 1}},
+	}
+	for _, center := range [][]float64{nil, {1, -2, 0.5}} {
+		for _, request := range requests {
+			transform, err := NewTransform(request, bounds, center, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			z := []float64{0.3, -1.7, 2.2}
+			back := transform.Apply(transform.Inverse(z))
+			for j := range z {
+				if math.Abs(back[j]-z[j]) > 1e-12 {
+					t.Errorf("%+v, center %v: Apply(Inverse(%v)) = %v", request, center, z, back)
+					break
+				}
+			}
+			if center != nil {
+				// оптимум переходит в точку Shift
+				if at := transform.Apply(transform.Shift); math.Abs(at[0]-center[0])+math.Abs(at[1]-center[1])+math.Abs(at[2]-center[2]) > 1e-12 {
+					t.Errorf("%+v: Apply(Shift) = %v, want %v", request, at, center)
+				}
+			}
+		}
+	}
+}
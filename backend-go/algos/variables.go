@@ -0,0 +1,175 @@
+package algos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+type VariableRequest struct {
+	// continuous, integer, discrete, categorical или binary
+	Type string `json:"type,omitempty"`
+	// шаг целочисленной переменной; значения — k·step в пределах границ
+	Step *float64 `json:"step,omitempty"`
+	// допустимые значения дискретной переменной
+	Values []float64 `json:"values,omitempty"`
+	// названия категорий; в позиции переменная — номер категории с 0
+	Categories []string `json:"categories,omitempty"`
+}
+
+// типы переменных
+const (
+	ContinuousVariable  = "continuous"
+	IntegerVariable     = "integer"
+	DiscreteVariable    = "discrete"
+	CategoricalVariable = "categorical"
+	BinaryVariable      = "binary"
+)
+
+// Variable — тип координаты решения. Алгоритмы двигают агентов в непрерывном
+// пространстве, а repair переносит каждую новую точку на ближайшее допустимое значение.
+type Variable struct {
+	Type       string
+	Step       float64
+	Values     []float64
+	Categories []string
+}
+
+func NewVariables(requests []VariableRequest) ([]Variable, error) {
+	variables := make([]Variable, len(requests))
+	for j, request := range requests {
+		variable := Variable{Type: request.Type, Step: setDefault(request.Step, 1)}
+		if variable.Type == "" {
+			variable.Type = ContinuousVariable
+		}
+
+		switch variable.Type {
+		case ContinuousVariable, BinaryVariable:
+		case IntegerVariable:
+			if variable.Step <= 0 {
+				return nil, fmt.Errorf("переменная %d: шаг должен быть больше 0", j+1)
+			}
+		case DiscreteVariable:
+			if len(request.Values) == 0 {
+				return nil, fmt.Errorf("переменная %d: не заданы допустимые значения", j+1)
+			}
+			variable.Values = slices.Clone(request.Values)
+			slices.Sort(variable.Values)
+		case CategoricalVariable:
+			if len(request.Categories) == 0 {
+				return nil, fmt.Errorf("переменная %d: не заданы категории", j+1)
+			}
+			variable.Categories = request.Categories
+		default:
+			return nil, fmt.Errorf("переменная %d: неизвестный тип %q", j+1, variable.Type)
+		}
+		variables[j] = variable
+	}
+	return variables, nil
+}
+
+// bounds возвращает границы, которые задаёт сам тип переменной
+func (v Variable) bounds() ([]float64, bool) {
+	switch v.Type {
+	case BinaryVariable:
+		return []float64{0, 1}, true
+	case DiscreteVariable:
+		return []float64{v.Values[0], v.Values[len(v.Values)-1]}, true
+	case CategoricalVariable:
+		return []float64{0, float64(len(v.Categories) - 1)}, true
+	}
+	return nil, false
+}
+
+// snap переносит значение на ближайшее допустимое в пределах границ
+func (v Variable) snap(value float64, bound []float64) float64 {
+	switch v.Type {
+	case IntegerVariable:
+		low, high := v.grid(bound)
+		value = math.Max(low, math.Min(v.Step*math.Round(value/v.Step), high))
+	case BinaryVariable, CategoricalVariable:
+		value = math.Round(value)
+	case DiscreteVariable:
+		i, _ := slices.BinarySearch(v.Values, value)
+		switch {
+		case i == len(v.Values):
+			value = v.Values[i-1]
+		case i > 0 && value-v.Values[i-1] < v.Values[i]-value:
+			value = v.Values[i-1]
+		default:
+			value = v.Values[i]
+		}
+	}
+	return value
+}
+
+// grid возвращает наименьшее и наибольшее кратные шагу значения в границах;
+// допуск защищает границы, кратные шагу, от ошибок округления
+func (v Variable) grid(bound []float64) (float64, float64) {
+	const tolerance = 1e-9
+	return v.Step * math.Ceil(bound[0]/v.Step-tolerance), v.Step * math.Floor(bound[1]/v.Step+tolerance)
+}
+
+// applyVariables проверяет типы переменных и сужает по ним границы поиска
+func (algo *Algo) applyVariables(requests []VariableRequest) error {
+	if requests == nil {
+		return nil
+	}
+	if len(requests) != algo.NumDimensions {
+		return errors.New("несоответствие числа типов переменных и размерности задачи")
+	}
+	variables, err := NewVariables(requests)
+	if err != nil {
+		return err
+	}
+	// границы могли прийти из запроса — не меняем их на месте
+	algo.Bounds = slices.Clone(algo.Bounds)
+	for j, variable := range variables {
+		if bound, ok := variable.bounds(); ok {
+			algo.Bounds[j] = bound
+		}
+		if variable.Type == IntegerVariable {
+			if low, high := variable.grid(algo.Bounds[j]); low > high {
+				return fmt.Errorf("переменная %d: в границах нет значений с шагом %v", j+1, variable.Step)
+			}
+		}
+	}
+	algo.Variables = variables
+	return nil
+}
+
+// repair возвращает точку в границы поиска и переносит дискретные координаты
+// на допустимые значения; точка изменяется на месте
+func (algo *Algo) repair(position []float64) []float64 {
+	for j := range position {
+		position[j] = math.Max(algo.Bounds[j][0], math.Min(position[j], algo.Bounds[j][1]))
+		if algo.Variables != nil {
+			position[j] = algo.Variables[j].snap(position[j], algo.Bounds[j])
+		}
+	}
+	return position
+}
+
+// continuous проверяет, что координата j непрерывна
+func (algo *Algo) continuous(j int) bool {
+	return algo.Variables == nil || algo.Variables[j].Type == ContinuousVariable
+}
+
+// labels возвращает названия категорий в точке; nil, если категориальных переменных нет
+func (algo *Algo) labels(position []float64) []string {
+	var labels []string
+	if position == nil {
+		return nil
+	}
+	for j, variable := range algo.Variables {
+		if variable.Type != CategoricalVariable {
+			continue
+		}
+		if labels == nil {
+			labels = make([]string, len(position))
+		}
+		labels[j] = variable.Categories[int(position[j])]
+	}
+	return labels
+}
@@ -0,0 +1,51 @@
+package algos
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestVariableSnap(t *testing.T) {
+	integer := Variable{Type: IntegerVariable, Step: 1}
+	tests := []struct {
+		name     string
+		variable Variable
+		bound    []float64
+		value    float64
+		want     float64
+	}{
+		{"целое", integer, []float64{-5, 5}, 2.4, 2},
+		{"сетка не привязана к нижней границе", integer, []float64{0.5, 10}, 3.4, 3},
+		{"у нижней границы — наименьший узел", integer, []float64{0.5, 10}, 0.5, 1},
+		{"у верхней границы — наибольший узел", integer, []float64{0.5, 10.7}, 10.7, 10},
+		{"ширина меньше шага", integer, []float64{2.8, 3.5}, 2.8, 3},
+		{"дробный шаг", Variable{Type: IntegerVariable, Step: 0.1}, []float64{0.3, 0.7}, 0.3, 0.3},
+		{"дробный шаг у верхней границы", Variable{Type: IntegerVariable, Step: 0.25}, []float64{0, 1.1}, 1.1, 1},
+		{"двоичная", Variable{Type: BinaryVariable}, []float64{0, 1}, 0.6, 1},
+		{"дискретная", Variable{Type: DiscreteVariable, Values: []float64{1, 2, 4, 8}}, []float64{1, 8}, 5.5, 4},
+		{"непрерывная", Variable{Type: ContinuousVariable}, []float64{0, 1}, 0.37, 0.37},
+	}
+	for _, test := range tests {
+		got := test.variable.snap(test.value, test.bound)
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%s: snap(%v) = %v, want %v", test.name, test.value, got, test.want)
+		}
+		if got < test.bound[0]-1e-12 || got > test.bound[1]+1e-12 {
+			t.Errorf("%s: snap(%v) = %v вне границ %v", test.name, test.value, got, test.bound)
+		}
+	}
+}
+
+func TestVariablesWithoutGridPoint(t *testing.T) {
+	dimensions, size := 1, 5
+	step := 1.0
+	_, err := NewAlgo(AlgoRequest{
+		Func: "x^2", Iterations: 1, NumDimensions: &dimensions, PopulationSize: &size,
+		Bounds:    [][]float64{{2.2, 2.9}},
+		Variables: []VariableRequest{{Type: IntegerVariable, Step: &step}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "нет значений с шагом") {
+		t.Errorf("границы без узлов сетки: %v", err)
+	}
+}
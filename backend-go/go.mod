@@ -5,6 +5,7 @@ go 1.24.0
 require (
 	github.com/expr-lang/expr v1.17.2
 	github.com/gorilla/websocket v1.5.3
+	github.com/seehuhn/mt19937 v1.0.0
 )
 
 require (
@@ -16,7 +17,6 @@ require (
 	github.com/campoy/embedmd v1.0.0 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/seehuhn/mt19937 v1.0.0 // indirect
 	golang.org/x/image v0.26.0 // indirect
 	golang.org/x/text v0.24.0 // indirect
 	gonum.org/v1/plot v0.16.0 // indirect
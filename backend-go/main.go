@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"graduate_work/algos"
 
@@ -17,6 +21,9 @@ var upgrader = websocket.Upgrader{
 	EnableCompression: true,
 }
 
+// наибольшее время работы одного алгоритма на сервере; 0 — без ограничения
+var runTimeout time.Duration
+
 type HandlerFunc func(*websocket.Conn, any) error
 
 type RequestType any
@@ -28,38 +35,88 @@ func handleWebSocket[T RequestType](w http.ResponseWriter, r *http.Request, cons
 		return
 	}
 
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		fmt.Println("Ошибка чтения запроса:", err)
+		return
+	}
 	var request T
-	if err := conn.ReadJSON(&request); err != nil {
+	if err := json.Unmarshal(data, &request); err != nil {
 		fmt.Println("Ошибка чтения JSON:", err)
 		return
 	}
 
+	// запрос с полем snapshot продолжает работу с сохранённого командой checkpoint снимка
+	var resume struct {
+		Snapshot []byte `json:"snapshot"`
+	}
+	if err := json.Unmarshal(data, &resume); err != nil {
+		fmt.Println("Ошибка чтения снимка:", err)
+		return
+	}
+	var snapshot *algos.Snapshot
+	if resume.Snapshot != nil {
+		snapshot = &algos.Snapshot{}
+		if err := snapshot.UnmarshalBinary(resume.Snapshot); err != nil {
+			fmt.Println("Ошибка чтения снимка:", err)
+			return
+		}
+	}
+
 	algorithm, err := constructor(request)
 	if err != nil {
 		fmt.Println("Ошибка инициализации алгоритма:", err)
 		return
 	}
 
+	// с ?paused=true алгоритм ждёт команды resume или step после начального ответа
+	paused := r.URL.Query().Get("paused") == "true"
+
 	go func() {
 		defer func() {
 			fmt.Println("Закрытие WebSocket соединения")
 			conn.Close()
+			if err := algorithm.Close(); err != nil {
+				fmt.Println("Ошибка завершения целевой функции:", err)
+			}
 		}()
 
 		disconnect := make(chan struct{})
+		commands := make(chan control)
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if runTimeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), runTimeout)
+		} else {
+			ctx, cancel = context.WithCancel(context.Background())
+		}
+		defer cancel()
 
 		go func() {
 			for {
-				_, _, err := conn.ReadMessage()
+				_, data, err := conn.ReadMessage()
 				if err != nil {
 					fmt.Println("Клиент отключился:", err)
 					close(disconnect)
+					// алгоритм останавливается, не дожидаясь следующей отправки
+					cancel()
 					return
 				}
+
+				var command control
+				if err := json.Unmarshal(data, &command); err != nil {
+					fmt.Println("Ошибка чтения команды:", err)
+					continue
+				}
+				select {
+				case commands <- command:
+				case <-ctx.Done():
+				}
 			}
 		}()
 
-		send := func(response algos.Response) error {
+		send := func(message any) error {
 			select {
 			case <-disconnect:
 				return fmt.Errorf("соединение с клиентом закрыто")
@@ -67,7 +124,7 @@ func handleWebSocket[T RequestType](w http.ResponseWriter, r *http.Request, cons
 			}
 
 			// fmt.Println(time.Now())
-			data, err := json.Marshal(response)
+			data, err := json.Marshal(message)
 			if err != nil {
 				fmt.Println("Ошибка формирования JSON:", err)
 				return err
@@ -82,12 +139,186 @@ func handleWebSocket[T RequestType](w http.ResponseWriter, r *http.Request, cons
 			return nil
 		}
 
-		algorithm.Run(send)
+		play(ctx, algorithm, snapshot, send, commands, paused)
 	}()
 
 }
 
+// control — команда клиента во время работы алгоритма
+type control struct {
+	// pause, resume, step, speed, state, checkpoint или stop
+	Command string `json:"command"`
+	// задержка между итерациями в миллисекундах для команды speed
+	Delay int `json:"delay,omitempty"`
+}
+
+// checkpointMessage — ответ на команду checkpoint: снимок в base64,
+// который клиент передаёт в поле snapshot запроса, чтобы продолжить работу
+type checkpointMessage struct {
+	Checkpoint []byte `json:"checkpoint"`
+	Iteration  int    `json:"iteration"`
+}
+
+// play выполняет алгоритм по итерациям, подчиняясь командам клиента;
+// со снимком работа продолжается с сохранённой итерации
+func play(ctx context.Context, algorithm algos.Algorithm, snapshot *algos.Snapshot, send func(any) error, commands <-chan control, paused bool) {
+	var initial algos.Response
+	if snapshot == nil {
+		initial = algorithm.Init(ctx)
+	} else {
+		var err error
+		if initial, err = algorithm.Resume(ctx, snapshot); err != nil {
+			fmt.Println("Ошибка восстановления из снимка:", err)
+			return
+		}
+	}
+	if err := send(initial); err != nil {
+		return
+	}
+
+	var delay time.Duration
+	finished := false
+	// step выполняет итерацию и отправляет ответ; false — продолжать не нужно
+	step := func() bool {
+		response, ok := algorithm.Step()
+		if !ok {
+			finished = true
+			return false
+		}
+		return send(response) == nil
+	}
+	// handle применяет команду; false — работа прекращена
+	handle := func(command control) bool {
+		switch command.Command {
+		case "pause":
+			paused = true
+		case "resume":
+			paused = false
+		case "step":
+			if paused {
+				return step()
+			}
+		case "speed":
+			delay = time.Duration(max(command.Delay, 0)) * time.Millisecond
+		case "state":
+			return send(algorithm.State()) == nil
+		case "checkpoint":
+			// команды обрабатываются между итерациями, поэтому снимок всегда полный
+			data, err := algorithm.Checkpoint().MarshalBinary()
+			if err != nil {
+				fmt.Println("Ошибка сохранения снимка:", err)
+				return true
+			}
+			return send(checkpointMessage{Checkpoint: data, Iteration: algorithm.State().Iteration}) == nil
+		case "stop":
+			return false
+		default:
+			fmt.Println("Неизвестная команда:", command.Command)
+		}
+		return true
+	}
+
+	for !finished {
+		var wait <-chan time.Time
+		if !paused {
+			if !step() {
+				return
+			}
+			if delay == 0 {
+				// без задержки команды проверяются между итерациями, не останавливая работу
+				select {
+				case command := <-commands:
+					if !handle(command) {
+						return
+					}
+				default:
+				}
+				continue
+			}
+			wait = time.After(delay)
+		}
+
+		select {
+		case command := <-commands:
+			if !handle(command) {
+				return
+			}
+			if wait != nil && !paused {
+				// после команды дожидаемся конца задержки
+				select {
+				case <-wait:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-wait:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleLandscape возвращает значения целевой функции на сетке для графиков
+func handleLandscape(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodPost:
+	default:
+		http.Error(w, "ожидается POST-запрос", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request algos.LandscapeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Ошибка чтения JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	landscape, err := algos.NewLandscape(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(landscape); err != nil {
+		fmt.Println("Ошибка записи ответа:", err)
+	}
+}
+
+// handleFunctions возвращает список зарегистрированных в Go целевых функций
+func handleFunctions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(algos.RegisteredFunctions()); err != nil {
+		fmt.Println("Ошибка записи ответа:", err)
+	}
+}
+
 func main() {
+	flag.DurationVar(&runTimeout, "timeout", 0, "Наибольшее время работы одного алгоритма (например, 30s); 0 — без ограничения")
+	externalPrograms := flag.String("external", "", "JSON-файл с внешними программами, которые клиенты могут выбрать по имени (например, {\"sim\":{\"command\":\"python3\",\"args\":[\"sim.py\"]}})")
+	httpObjectives := flag.String("http", "", "Адреса серверов целевой функции через запятую, которые клиенты могут указать в запросе")
+	flag.Parse()
+
+	// без списка внешние программы запрещены: клиент выбирает программу только по имени
+	if *externalPrograms != "" {
+		if err := algos.LoadExternalPrograms(*externalPrograms); err != nil {
+			fmt.Println("Ошибка загрузки списка внешних программ:", err)
+			return
+		}
+	}
+	if *httpObjectives != "" {
+		if err := algos.AllowHTTPObjective(strings.Split(*httpObjectives, ",")...); err != nil {
+			fmt.Println("Ошибка в списке серверов целевой функции:", err)
+			return
+		}
+	}
+
 	http.HandleFunc("/ws/AFSA", func(w http.ResponseWriter, r *http.Request) {
 		handleWebSocket(w, r, algos.NewAFSA)
 	})
@@ -103,6 +334,17 @@ func main() {
 	http.HandleFunc("/ws/GWO", func(w http.ResponseWriter, r *http.Request) {
 		handleWebSocket(w, r, algos.NewGWO)
 	})
+	http.HandleFunc("/ws/MOGWO", func(w http.ResponseWriter, r *http.Request) {
+		handleWebSocket(w, r, algos.NewMOGWO)
+	})
+	http.HandleFunc("/ws/MOFA", func(w http.ResponseWriter, r *http.Request) {
+		handleWebSocket(w, r, algos.NewMOFA)
+	})
+	http.HandleFunc("/ws/MOABC", func(w http.ResponseWriter, r *http.Request) {
+		handleWebSocket(w, r, algos.NewMOABC)
+	})
+	http.HandleFunc("/landscape", handleLandscape)
+	http.HandleFunc("/functions", handleFunctions)
 
 	fmt.Println("Сервер запущен на :8080")
 	err := http.ListenAndServe(":8080", nil)
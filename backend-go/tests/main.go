@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	test "graduate_work/algos"
+	"os"
+	"os/signal"
 	"strings"
 	"time"
 )
@@ -20,20 +23,59 @@ func CopySlice(slice [][]float64) [][]float64 {
 
 type RequestType any
 
-func TestAlgo[T RequestType](request T, constructor func(T) (test.Algorithm, error)) {
+// checkpointing — сохранение снимков состояния и продолжение работы с них
+type checkpointing struct {
+	// файл снимка, с которого продолжается работа
+	resume string
+	// файл, куда снимок записывается каждые every итераций и по окончании работы
+	path  string
+	every int
+	// закрывается по Ctrl+C: работа прекращается после текущей итерации
+	interrupt <-chan struct{}
+}
+
+func (c checkpointing) enabled() bool {
+	return c.resume != "" || c.path != ""
+}
+
+func TestAlgo[T RequestType](ctx context.Context, request T, constructor func(T) (test.Algorithm, error), reference *test.MultiObjectiveBenchmark, checkpoint checkpointing) {
 	algorithm, err := constructor(request)
 	if err != nil {
 		fmt.Println("Ошибка инициализации алгоритма:", err)
 		return
 	}
+	defer algorithm.Close()
 
 	var history [][][]float64
+	var front *test.ParetoFront
+	var fit *test.FitResult
+	var evaluations, iterations int
+	var stopReason, summary string
 
-	start := time.Now()
-	bestPos, bestVal := algorithm.Run(func(resp test.Response) error {
+	record := func(resp test.Response) error {
 		history = append(history, CopySlice(resp.StepPositions))
+		front = resp.ParetoFront
+		evaluations, iterations = resp.Evaluations, resp.Iteration
+		if resp.Fit != nil {
+			fit = resp.Fit
+		}
+		if resp.StopReason != "" {
+			stopReason, summary = resp.StopReason, resp.Summary
+		}
 		return nil
-	})
+	}
+
+	start := time.Now()
+	var bestPos []float64
+	var bestVal float64
+	if checkpoint.enabled() {
+		if bestPos, bestVal, err = runWithCheckpoints(ctx, algorithm, record, checkpoint); err != nil {
+			fmt.Println(err)
+			return
+		}
+	} else {
+		bestPos, bestVal = algorithm.Run(ctx, record)
+	}
 	elapsed := time.Since(start)
 
 	result := map[string]any{
@@ -41,21 +83,125 @@ func TestAlgo[T RequestType](request T, constructor func(T) (test.Algorithm, err
 		"best_position": bestPos,
 		"best_value":    bestVal,
 		"time":          elapsed.Seconds(),
+		"evaluations":   evaluations,
+		"iterations":    iterations,
+		"stop_reason":   stopReason,
+		"summary":       summary,
+	}
+
+	if fit != nil {
+		result["fit"] = fit
+	}
+
+	if front != nil {
+		result["pareto_front"] = front.Values
+		// качество фронта относительно эталонного фронта тестовой задачи
+		if reference != nil {
+			result["hypervolume"] = test.Hypervolume(front.Values, reference.ReferencePoint)
+			result["igd"] = test.IGD(front.Values, reference.ReferenceFront(500))
+		}
 	}
 
 	jsonOutput, _ := json.MarshalIndent(result, "", "  ")
 	fmt.Println(string(jsonOutput))
 }
 
+// runWithCheckpoints выполняет алгоритм по итерациям, начиная со снимка,
+// если он задан, и сохраняя снимки по ходу работы
+func runWithCheckpoints(ctx context.Context, algorithm test.Algorithm, record func(test.Response) error, checkpoint checkpointing) ([]float64, float64, error) {
+	var initial test.Response
+	if checkpoint.resume == "" {
+		initial = algorithm.Init(ctx)
+	} else {
+		data, err := os.ReadFile(checkpoint.resume)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Ошибка чтения снимка: %w", err)
+		}
+		var snapshot test.Snapshot
+		if err := snapshot.UnmarshalBinary(data); err != nil {
+			return nil, 0, fmt.Errorf("Ошибка чтения снимка: %w", err)
+		}
+		if initial, err = algorithm.Resume(ctx, &snapshot); err != nil {
+			return nil, 0, fmt.Errorf("Ошибка восстановления из снимка: %w", err)
+		}
+	}
+	record(initial)
+
+	save := func() error {
+		if checkpoint.path == "" {
+			return nil
+		}
+		data, err := algorithm.Checkpoint().MarshalBinary()
+		if err == nil {
+			err = os.WriteFile(checkpoint.path, data, 0o644)
+		}
+		if err != nil {
+			return fmt.Errorf("Ошибка сохранения снимка: %w", err)
+		}
+		return nil
+	}
+
+loop:
+	for {
+		select {
+		case <-checkpoint.interrupt:
+			break loop
+		default:
+		}
+		response, ok := algorithm.Step()
+		if !ok {
+			break
+		}
+		record(response)
+		if checkpoint.every > 0 && response.Iteration%checkpoint.every == 0 {
+			if err := save(); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if err := save(); err != nil {
+		return nil, 0, err
+	}
+
+	final := algorithm.State()
+	return final.BestPosition, final.BestValue, nil
+}
+
 func main() {
 	// флаги для командной строки
 	algoName := flag.String("algorithm", "GWO", "Название алгоритма (например, GWO, AFSA, SFLA)")
 	function := flag.String("function", "x+y", "Целевая функция")
+	native := flag.String("native", "", "Имя зарегистрированной в Go целевой функции")
+	benchmark := flag.String("benchmark", "", "Название встроенной тестовой функции (например, rastrigin, ackley)")
+	dimensions := flag.Int("dimensions", 2, "Размерность задачи для встроенной тестовой функции")
+	direction := flag.String("objective", "min", "Направление оптимизации: min или max")
+	objectives := flag.String("objectives", "", "Целевые функции многокритериальной задачи в формате JSON (например, [\"x^2\",\"(x-2)^2\"])")
+	archiveSize := flag.Int("archiveSize", 100, "Размер архива Парето для многокритериальных алгоритмов")
+	helpers := flag.String("helpers", "", "Вспомогательные функции в формате JSON (например, [\"r(a, b) = sqrt(a^2 + b^2)\"])")
+	transform := flag.String("transform", "", "Сдвиг, поворот и масштаб задачи в формате JSON (например, {\"randomShift\":true,\"rotate\":true,\"bias\":100})")
+	noise := flag.String("noise", "", "Шум целевой функции в формате JSON (например, {\"level\":0.1,\"resampling\":\"fixed\"})")
+	movingPeaks := flag.String("movingPeaks", "", "Параметры генератора Moving Peaks в формате JSON (для -benchmark movingPeaks)")
+	changeDetection := flag.String("changeDetection", "", "Обнаружение изменений целевой функции в формате JSON (например, {\"diversify\":0.3})")
+	external := flag.String("external", "", "Внешняя программа, вычисляющая целевую функцию, в формате JSON (например, {\"command\":\"python3\",\"args\":[\"sim.py\"]})")
+	remote := flag.String("http", "", "HTTP-сервер, вычисляющий целевую функцию, в формате JSON (например, {\"url\":\"http://localhost:8090/evaluate\"})")
+	fit := flag.String("fit", "", "Подбор параметров модели в формате JSON (например, {\"model\":\"a*exp(-b*t)+c\",\"loss\":\"mse\"})")
+	localSearch := flag.String("localSearch", "", "Градиентное уточнение лучшего решения в формате JSON (например, {\"method\":\"lbfgs\",\"every\":10})")
+	fitCSV := flag.String("fitCSV", "", "CSV-файл с данными для подбора параметров модели")
+	constraints := flag.String("constraints", "", "Ограничения в формате JSON (например, {\"inequalities\":[\"1-x-y\"],\"method\":\"feasibility\"})")
+	boundary := flag.String("boundary", "", "Обработка выхода за границы в формате JSON (например, {\"method\":\"reflect\"}; clamp, reflect, wrap, random, midpoint, penalty)")
+	initialization := flag.String("initialization", "", "Способ создания начальной популяции в формате JSON (например, {\"method\":\"sobol\"}; uniform, lhs, sobol, halton, opposition, gaussian, previous)")
+	stopping := flag.String("stopping", "", "Критерии досрочной остановки в формате JSON (например, {\"target\":1e-6,\"tolerance\":1e-9,\"window\":20,\"diameter\":1e-4})")
+	variables := flag.String("variables", "", "Типы координат в формате JSON (например, [{\"type\":\"integer\"},{\"type\":\"categorical\",\"categories\":[\"сталь\",\"алюминий\"]}])")
 	iterations := flag.Int("iterations", 100, "Количество итераций")
+	timeout := flag.Duration("timeout", 0, "Ограничение времени работы алгоритма (например, 10s); 0 — без ограничения")
+	maxEvaluations := flag.Int("maxEvaluations", 0, "Бюджет вычислений целевой функции (0 — без ограничения)")
 	bounds := flag.String("bounds", "[[-5,5],[-5,5]]", "Границы для каждого измерения (например, [-5,5],[-5,5])")
 	population := flag.String("population", "", "Начальная популяция")
 	population_size := flag.Int("population_size", 50, "Размер начальной популяции")
 	seed := flag.Int("seed", 1, "Seed")
+	checkpointPath := flag.String("checkpoint", "", "Файл снимка состояния: записывается по окончании работы, по Ctrl+C и каждые checkpointEvery итераций")
+	checkpointEvery := flag.Int("checkpointEvery", 0, "Период записи снимка в итерациях; 0 — только по окончании работы")
+	resume := flag.String("resume", "", "Файл снимка, с которого продолжается работа; остальные флаги должны совпадать с исходным запуском")
 
 	// GWO
 	initialA := flag.Float64("initialA", 2.0, "Начальное значение A")
@@ -82,18 +228,195 @@ func main() {
 
 	flag.Parse()
 
-	parsedBounds, err := parseMatrix(*bounds)
-	if err != nil {
-		fmt.Println("Ошибка при разборе границ:", err)
-		return
-	}
+	setFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
 
 	// общий запрос
 	algoRequest := test.AlgoRequest{
 		Func:       *function,
 		Iterations: *iterations,
-		Bounds:     parsedBounds,
 		Seed:       seed,
+		Benchmark:  *benchmark,
+		Native:     *native,
+		Direction:  *direction,
+	}
+
+	// для встроенной функции без явно заданных границ используются её границы
+	// по умолчанию, а без явно заданной размерности — её размерность по умолчанию
+	if *benchmark != "" && !setFlags["bounds"] {
+		_, multi := test.MultiObjectiveBenchmarks[*benchmark]
+		if !(multi || *benchmark == test.MovingPeaksBenchmark) || setFlags["dimensions"] {
+			algoRequest.NumDimensions = dimensions
+		}
+	} else {
+		parsedBounds, err := parseMatrix(*bounds)
+		if err != nil {
+			fmt.Println("Ошибка при разборе границ:", err)
+			return
+		}
+		algoRequest.Bounds = parsedBounds
+	}
+
+	if *objectives != "" {
+		if err := json.Unmarshal([]byte(*objectives), &algoRequest.Objectives); err != nil {
+			fmt.Println("Ошибка при разборе целевых функций:", err)
+			return
+		}
+	}
+	algoRequest.ArchiveSize = archiveSize
+
+	if *maxEvaluations > 0 {
+		algoRequest.MaxEvaluations = maxEvaluations
+	}
+
+	if *helpers != "" {
+		if err := json.Unmarshal([]byte(*helpers), &algoRequest.Helpers); err != nil {
+			fmt.Println("Ошибка при разборе вспомогательных функций:", err)
+			return
+		}
+	}
+
+	if *transform != "" {
+		var parsedTransform test.TransformRequest
+		if err := json.Unmarshal([]byte(*transform), &parsedTransform); err != nil {
+			fmt.Println("Ошибка при разборе преобразования задачи:", err)
+			return
+		}
+		algoRequest.Transform = &parsedTransform
+	}
+
+	if *noise != "" {
+		var parsedNoise test.NoiseRequest
+		if err := json.Unmarshal([]byte(*noise), &parsedNoise); err != nil {
+			fmt.Println("Ошибка при разборе параметров шума:", err)
+			return
+		}
+		algoRequest.Noise = &parsedNoise
+	}
+
+	if *movingPeaks != "" {
+		var parsedMovingPeaks test.MovingPeaksRequest
+		if err := json.Unmarshal([]byte(*movingPeaks), &parsedMovingPeaks); err != nil {
+			fmt.Println("Ошибка при разборе параметров Moving Peaks:", err)
+			return
+		}
+		algoRequest.MovingPeaks = &parsedMovingPeaks
+	}
+
+	if *changeDetection != "" {
+		var parsedChangeDetection test.ChangeDetectionRequest
+		if err := json.Unmarshal([]byte(*changeDetection), &parsedChangeDetection); err != nil {
+			fmt.Println("Ошибка при разборе параметров обнаружения изменений:", err)
+			return
+		}
+		algoRequest.ChangeDetection = &parsedChangeDetection
+	}
+
+	if *external != "" {
+		var parsedExternal struct {
+			test.ExternalProgram
+			test.ExternalRequest
+		}
+		if err := json.Unmarshal([]byte(*external), &parsedExternal); err != nil {
+			fmt.Println("Ошибка при разборе параметров внешней программы:", err)
+			return
+		}
+		// в CLI команду задаёт сам пользователь, поэтому она разрешается под своим именем
+		if parsedExternal.Command != "" {
+			if parsedExternal.Program == "" {
+				parsedExternal.Program = parsedExternal.Command
+			}
+			if err := test.RegisterExternal(parsedExternal.Program, parsedExternal.ExternalProgram); err != nil {
+				fmt.Println("Ошибка при разборе параметров внешней программы:", err)
+				return
+			}
+		}
+		algoRequest.External = &parsedExternal.ExternalRequest
+	}
+
+	if *remote != "" {
+		var parsedRemote test.HTTPObjectiveRequest
+		if err := json.Unmarshal([]byte(*remote), &parsedRemote); err != nil {
+			fmt.Println("Ошибка при разборе параметров HTTP-сервера:", err)
+			return
+		}
+		// в CLI адрес задаёт сам пользователь
+		if err := test.AllowHTTPObjective(parsedRemote.URL); err != nil {
+			fmt.Println("Ошибка при разборе параметров HTTP-сервера:", err)
+			return
+		}
+		algoRequest.HTTP = &parsedRemote
+	}
+
+	if *fit != "" {
+		var parsedFit test.FitRequest
+		if err := json.Unmarshal([]byte(*fit), &parsedFit); err != nil {
+			fmt.Println("Ошибка при разборе параметров подбора модели:", err)
+			return
+		}
+		if *fitCSV != "" {
+			data, err := os.ReadFile(*fitCSV)
+			if err != nil {
+				fmt.Println("Ошибка чтения данных:", err)
+				return
+			}
+			parsedFit.CSV = string(data)
+		}
+		algoRequest.Fit = &parsedFit
+	}
+
+	if *localSearch != "" {
+		var parsedLocalSearch test.LocalSearchRequest
+		if err := json.Unmarshal([]byte(*localSearch), &parsedLocalSearch); err != nil {
+			fmt.Println("Ошибка при разборе параметров локального поиска:", err)
+			return
+		}
+		algoRequest.LocalSearch = &parsedLocalSearch
+	}
+
+	if *boundary != "" {
+		var parsedBoundary test.BoundaryRequest
+		if err := json.Unmarshal([]byte(*boundary), &parsedBoundary); err != nil {
+			fmt.Println("Ошибка при разборе обработки границ:", err)
+			return
+		}
+		algoRequest.Boundary = &parsedBoundary
+	}
+
+	if *initialization != "" {
+		var parsedInitialization test.InitializationRequest
+		if err := json.Unmarshal([]byte(*initialization), &parsedInitialization); err != nil {
+			fmt.Println("Ошибка при разборе способа создания популяции:", err)
+			return
+		}
+		algoRequest.Initialization = &parsedInitialization
+	}
+
+	if *stopping != "" {
+		var parsedStopping test.StoppingRequest
+		if err := json.Unmarshal([]byte(*stopping), &parsedStopping); err != nil {
+			fmt.Println("Ошибка при разборе критериев остановки:", err)
+			return
+		}
+		algoRequest.Stopping = &parsedStopping
+	}
+
+	if *variables != "" {
+		if err := json.Unmarshal([]byte(*variables), &algoRequest.Variables); err != nil {
+			fmt.Println("Ошибка при разборе типов координат:", err)
+			return
+		}
+	}
+
+	if *constraints != "" {
+		var parsedConstraints test.ConstraintsRequest
+		if err := json.Unmarshal([]byte(*constraints), &parsedConstraints); err != nil {
+			fmt.Println("Ошибка при разборе ограничений:", err)
+			return
+		}
+		algoRequest.Constraints = &parsedConstraints
 	}
 
 	if *population == "" {
@@ -175,12 +498,65 @@ func main() {
 			return test.NewSFLA(req.(test.SFLARequest))
 		}
 
+	case "MOGWO":
+		request = test.GWORequest{
+			AlgoRequest: algoRequest,
+			InitialA:    initialA,
+			InitialC:    initialC,
+		}
+		constructor = func(req RequestType) (test.Algorithm, error) {
+			return test.NewMOGWO(req.(test.GWORequest))
+		}
+
+	case "MOFA":
+		request = test.FARequest{
+			AlgoRequest: algoRequest,
+			Beta0:       beta0,
+			Gamma:       gamma,
+			Alpha:       alpha,
+		}
+		constructor = func(req RequestType) (test.Algorithm, error) {
+			return test.NewMOFA(req.(test.FARequest))
+		}
+
+	case "MOABC":
+		request = test.ABCRequest{
+			AlgoRequest: algoRequest,
+			Limit:       limit,
+			ForagerSize: foragerSize,
+		}
+		constructor = func(req RequestType) (test.Algorithm, error) {
+			return test.NewMOABC(req.(test.ABCRequest))
+		}
+
 	default:
 		fmt.Println("Неизвестный алгоритм:", *algoName)
 		return
 	}
 
-	TestAlgo(request, constructor)
+	var reference *test.MultiObjectiveBenchmark
+	if b, ok := test.MultiObjectiveBenchmarks[*benchmark]; ok {
+		reference = &b
+	}
+
+	checkpoint := checkpointing{resume: *resume, path: *checkpointPath, every: *checkpointEvery}
+
+	// по Ctrl+C алгоритм останавливается и выводит лучшее найденное решение;
+	// при сохранении снимков — после текущей итерации, чтобы снимок был полным
+	interrupt, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx := interrupt
+	if checkpoint.enabled() {
+		ctx = context.Background()
+		checkpoint.interrupt = interrupt.Done()
+	}
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	TestAlgo(ctx, request, constructor, reference, checkpoint)
 }
 
 // парсинг строки в срез
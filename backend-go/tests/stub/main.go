@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"graduate_work/algos"
+)
+
+// Заглушка сервера целевой функции для проверки HTTP-вычислителя:
+// вычисляет выражение в присланных точках и может имитировать сбои и задержки.
+func main() {
+	addr := flag.String("addr", ":8090", "Адрес сервера")
+	function := flag.String("function", "x^2+y^2", "Целевая функция")
+	failRate := flag.Float64("failRate", 0, "Доля запросов, на которые сервер отвечает 503")
+	delay := flag.Duration("delay", 0, "Задержка ответа")
+	flag.Parse()
+
+	expression, err := algos.CompileExpression(*function)
+	if err != nil {
+		fmt.Println("Ошибка компиляции функции:", err)
+		return
+	}
+
+	var requests atomic.Int64
+	http.HandleFunc("/evaluate", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "ожидается POST", http.StatusMethodNotAllowed)
+			return
+		}
+		number := requests.Add(1)
+
+		var request struct {
+			Positions [][]float64 `json:"positions"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		time.Sleep(*delay)
+		if rand.Float64() < *failRate {
+			http.Error(w, "сбой", http.StatusServiceUnavailable)
+			return
+		}
+
+		response := map[string]any{}
+		values := make([]float64, len(request.Positions))
+		for i, position := range request.Positions {
+			if len(position) < expression.Dimensions {
+				response["error"] = fmt.Sprintf("точка %d: ожидается %d координат", i, expression.Dimensions)
+				break
+			}
+			values[i] = expression.Eval(position)
+		}
+		if response["error"] == nil {
+			response["values"] = values
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		fmt.Println("Запрос", number, "точек:", len(request.Positions))
+	})
+
+	fmt.Println("Сервер целевой функции запущен на", *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		fmt.Println("Ошибка запуска сервера:", err)
+	}
+}